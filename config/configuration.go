@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -12,17 +14,39 @@ import (
 
 // Config : application configuration
 type Config struct {
-	ConsumerKey               string         `json:"CONSUMER_KEY" envconfig:"CONSUMER_KEY"`
-	ConsumerSecret            string         `json:"CONSUMER_SECRET" envconfig:"CONSUMER_SECRET"`
-	AccessToken               string         `json:"ACCESS_TOKEN" envconfig:"ACCESS_TOKEN"`
-	AccessTokenSecret         string         `json:"ACCESS_TOKEN_SECRET" envconfig:"ACCESS_TOKEN_SECRET"`
-	SearchUser                string         `json:"SEARCH_USER" envconfig:"SEARCH_USER"`
+	ConsumerKey       string `json:"CONSUMER_KEY" envconfig:"CONSUMER_KEY"`
+	ConsumerSecret    string `json:"CONSUMER_SECRET" envconfig:"CONSUMER_SECRET"`
+	AccessToken       string `json:"ACCESS_TOKEN" envconfig:"ACCESS_TOKEN"`
+	AccessTokenSecret string `json:"ACCESS_TOKEN_SECRET" envconfig:"ACCESS_TOKEN_SECRET"`
+	SearchUser        string `json:"SEARCH_USER" envconfig:"SEARCH_USER"`
+	// Seeds : the users to start with, "handle", "id:ID", "list:ID", "list:OWNER/SLUG",
+	// "following_not_followers:handle" or "file:path" with one seed per line
+	Seeds                     []string       `json:"SEEDS" envconfig:"SEEDS"`
 	TwitterList               TwitterList    `json:"TWITTER_LIST" envconfig:"TWITTER_LIST"`
 	SearchCriteria            SearchCriteria `json:"SEARCH_CRITERIA" envconfig:"SEARCH_CRITERIA"`
 	Following                 bool           `json:"FOLLOWING" envconfig:"FOLLOWING"`
 	Followers                 bool           `json:"FOLLOWERS" envconfig:"FOLLOWERS"`
 	Recursive                 bool           `json:"RECURSIVE" envconfig:"RECURSIVE"`
 	RecursiveSuccessUsersOnly bool           `json:"RECURSIVE_SUCCESS_USERS_ONLY" envconfig:"RECURSIVE_SUCCESS_USERS_ONLY"`
+	// MaxDepth : the users found MAX_DEPTH hops from the seeds are not investigated, 0 is unlimited
+	MaxDepth int64 `json:"MAX_DEPTH" envconfig:"MAX_DEPTH"`
+	// FrontierOrder : the order of the users under investigation, FIFO (default), LIFO, SCORE or FOLLOWERS
+	FrontierOrder string `json:"FRONTIER_ORDER" envconfig:"FRONTIER_ORDER"`
+	// Budget : the limits of the run, the pipeline stops itself when one of them is reached
+	Budget Budget `json:"BUDGET" envconfig:"BUDGET"`
+}
+
+// Budget : run limits, 0 is unlimited
+type Budget struct {
+	MaxMatches int64 `json:"MAX_MATCHES" envconfig:"MAX_MATCHES"`
+	// MaxProfiles : the looked up users profiles
+	MaxProfiles int64 `json:"MAX_PROFILES" envconfig:"MAX_PROFILES"`
+	// MaxAPICalls : the calls per twitter API endpoint e.g. {"users/lookup": 900, "followers/ids": 15}
+	MaxAPICalls        map[string]int64 `json:"MAX_API_CALLS" envconfig:"MAX_API_CALLS"`
+	MaxDurationMinutes int64            `json:"MAX_DURATION_MINUTES" envconfig:"MAX_DURATION_MINUTES"`
+	// MaxDepthReached : the run stops when a user at this depth is about to be investigated,
+	// the users are investigated by depth with the FIFO frontier only, the other orders are rejected.
+	MaxDepthReached int64 `json:"MAX_DEPTH_REACHED" envconfig:"MAX_DEPTH_REACHED"`
 }
 
 // SearchCriteria : application Search Criteria
@@ -37,7 +61,170 @@ type SearchCriteria struct {
 	TweetsCountBetween    FromToNumber `json:"TWEETS_COUNT_BETWEEN" envconfig:"TWEETS_COUNT_BETWEEN"`
 	ListsCountBetween     FromToNumber `json:"LISTS_COUNT_BETWEEN" envconfig:"LISTS_COUNT_BETWEEN"`
 	JoinedBetween         FromToDate   `json:"JOINED_BETWEEN" envconfig:"JOINED_BETWEEN"`
-	Verified              bool         `json:"VERIFIED" envconfig:"VERIFIED"`
+	// profile url and bio entities e.g. "github.com", "@company", "#golang", "*" for any
+	ProfileURLDomains []string `json:"PROFILE_URL_DOMAINS" envconfig:"PROFILE_URL_DOMAINS"`
+	BioURLDomains     []string `json:"BIO_URL_DOMAINS" envconfig:"BIO_URL_DOMAINS"`
+	BioMentions       []string `json:"BIO_MENTIONS" envconfig:"BIO_MENTIONS"`
+	BioHashtags       []string `json:"BIO_HASHTAGS" envconfig:"BIO_HASHTAGS"`
+	BioCashtags       []string `json:"BIO_CASHTAGS" envconfig:"BIO_CASHTAGS"`
+	BioEmails         []string `json:"BIO_EMAILS" envconfig:"BIO_EMAILS"`
+	// last activity, users without visible status are handled by MISSING_STATUS
+	LastTweetedBetween  FromToDate `json:"LAST_TWEETED_BETWEEN" envconfig:"LAST_TWEETED_BETWEEN"`
+	ExcludeInactiveDays int64      `json:"EXCLUDE_INACTIVE_DAYS" envconfig:"EXCLUDE_INACTIVE_DAYS"`
+	MissingStatus       string     `json:"MISSING_STATUS" envconfig:"MISSING_STATUS"`
+	// resolved location, ISO 3166-1 alpha-2 country codes e.g. "DE" and radius search
+	LocationCountries []string     `json:"LOCATION_COUNTRIES" envconfig:"LOCATION_COUNTRIES"`
+	LocationNear      LocationNear `json:"LOCATION_NEAR" envconfig:"LOCATION_NEAR"`
+	// detected language of the bio and name, ISO 639-1 codes e.g. "de", "ar"
+	Languages          []string `json:"LANGUAGES" envconfig:"LANGUAGES"`
+	LanguageConfidence float64  `json:"LANGUAGE_CONFIDENCE" envconfig:"LANGUAGE_CONFIDENCE"`
+	// derived metrics
+	FollowersRatioBetween       FromToFloat  `json:"FOLLOWERS_RATIO_BETWEEN" envconfig:"FOLLOWERS_RATIO_BETWEEN"`
+	AccountAgeDaysBetween       FromToNumber `json:"ACCOUNT_AGE_DAYS_BETWEEN" envconfig:"ACCOUNT_AGE_DAYS_BETWEEN"`
+	TweetsPerDayBetween         FromToFloat  `json:"TWEETS_PER_DAY_BETWEEN" envconfig:"TWEETS_PER_DAY_BETWEEN"`
+	LikesPerTweetBetween        FromToFloat  `json:"LIKES_PER_TWEET_BETWEEN" envconfig:"LIKES_PER_TWEET_BETWEEN"`
+	ListedPer1KFollowersBetween FromToFloat  `json:"LISTED_PER_1K_FOLLOWERS_BETWEEN" envconfig:"LISTED_PER_1K_FOLLOWERS_BETWEEN"`
+	// MaxBotScore : users with bot score (0 to 1) of MAX_BOT_SCORE or more are excluded, 0 is disabled
+	MaxBotScore float64 `json:"MAX_BOT_SCORE" envconfig:"MAX_BOT_SCORE"`
+	// profile flags, REQUIRE, EXCLUDE or IGNORE (PROTECTED default EXCLUDE, the others IGNORE)
+	Verified             TriState `json:"VERIFIED" envconfig:"VERIFIED"`
+	Protected            TriState `json:"PROTECTED" envconfig:"PROTECTED"`
+	DefaultProfile       TriState `json:"DEFAULT_PROFILE" envconfig:"DEFAULT_PROFILE"`
+	DefaultProfileImage  TriState `json:"DEFAULT_PROFILE_IMAGE" envconfig:"DEFAULT_PROFILE_IMAGE"`
+	GeoEnabled           TriState `json:"GEO_ENABLED" envconfig:"GEO_ENABLED"`
+	Query                string   `json:"QUERY" envconfig:"QUERY"`
+	DisableNormalization bool     `json:"DISABLE_NORMALIZATION" envconfig:"DISABLE_NORMALIZATION"`
+	Scoring              Scoring  `json:"SCORING" envconfig:"SCORING"`
+	// CustomFilters : filters registered with finder.RegisterFilter, evaluated with the other criteria
+	CustomFilters []CustomFilter `json:"CUSTOM_FILTERS" envconfig:"CUSTOM_FILTERS"`
+}
+
+const (
+	// MissingStatusExclude : last activity criteria fail for users without visible status (default)
+	MissingStatusExclude = "EXCLUDE"
+	// MissingStatusInclude : last activity criteria pass for users without visible status
+	MissingStatusInclude = "INCLUDE"
+)
+
+// TriState : boolean criteria that can be required, excluded or ignored
+type TriState string
+
+const (
+	// TriStateIgnore : the flag is not checked
+	TriStateIgnore TriState = "IGNORE"
+	// TriStateRequire : the flag should be true
+	TriStateRequire TriState = "REQUIRE"
+	// TriStateExclude : the flag should be false
+	TriStateExclude TriState = "EXCLUDE"
+)
+
+// TriStates : all the valid TriState values
+var TriStates = []TriState{TriStateIgnore, TriStateRequire, TriStateExclude}
+
+// Or : the state or def if not set
+func (t TriState) Or(def TriState) TriState {
+	if t == "" {
+		return def
+	}
+	return t.normalized()
+}
+
+// normalized : the state name in upper case
+func (t TriState) normalized() TriState {
+	return TriState(strings.ToUpper(strings.TrimSpace(string(t))))
+}
+
+// Validate : check the state is one of TriStates or not set
+func (t TriState) Validate() error {
+	switch t.normalized() {
+	case "", TriStateIgnore, TriStateRequire, TriStateExclude:
+		return nil
+	}
+	return fmt.Errorf("invalid state %q, expected %v, %v or %v", string(t), TriStateRequire, TriStateExclude, TriStateIgnore)
+}
+
+// UnmarshalJSON : accept the state name or the old boolean value,
+// true is REQUIRE and false is IGNORE as before.
+func (t *TriState) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*t = TriStateIgnore
+		if b {
+			*t = TriStateRequire
+		}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid state %s, expected true, false, %v, %v or %v", data, TriStateRequire, TriStateExclude, TriStateIgnore)
+	}
+	return t.Decode(s)
+}
+
+// Decode : envconfig decoder, same values as the json
+func (t *TriState) Decode(value string) error {
+	if b, err := strconv.ParseBool(value); err == nil {
+		*t = TriStateIgnore
+		if b {
+			*t = TriStateRequire
+		}
+		return nil
+	}
+	v := TriState(value)
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	*t = v.normalized()
+	return nil
+}
+
+// Validate : check the values of the search criteria that are not checked by the json and envconfig decoders
+// e.g. set by SetConfiguration, the TriState flags and MISSING_STATUS
+func (sc SearchCriteria) Validate() error {
+	switch strings.ToUpper(sc.MissingStatus) {
+	case "", MissingStatusExclude, MissingStatusInclude:
+	default:
+		return fmt.Errorf("unknown MISSING_STATUS %q, expected %v or %v", sc.MissingStatus, MissingStatusExclude, MissingStatusInclude)
+	}
+	flags := []struct {
+		name  string
+		state TriState
+	}{
+		{"VERIFIED", sc.Verified},
+		{"PROTECTED", sc.Protected},
+		{"DEFAULT_PROFILE", sc.DefaultProfile},
+		{"DEFAULT_PROFILE_IMAGE", sc.DefaultProfileImage},
+		{"GEO_ENABLED", sc.GeoEnabled},
+	}
+	for _, f := range flags {
+		if err := f.state.Validate(); err != nil {
+			return fmt.Errorf("%v: %v", f.name, err)
+		}
+	}
+	return nil
+}
+
+// Scoring : weighted scoring mode instead of pass/fail filtering
+type Scoring struct {
+	Enabled bool `json:"ENABLED" envconfig:"ENABLED"`
+	// Threshold : the user match if the total score reach the threshold
+	Threshold float64 `json:"THRESHOLD" envconfig:"THRESHOLD"`
+	// RecursiveThreshold : threshold to investigate the user with RECURSIVE_SUCCESS_USERS_ONLY
+	RecursiveThreshold float64 `json:"RECURSIVE_THRESHOLD" envconfig:"RECURSIVE_THRESHOLD"`
+	// Weights : weight of every criteria by name e.g. {"BIO": 3}, default 1
+	Weights map[string]float64 `json:"WEIGHTS" envconfig:"WEIGHTS"`
+}
+
+// LocationNear : within KM of the place, city name or "LAT,LON"
+type LocationNear struct {
+	Place string  `json:"PLACE" envconfig:"PLACE"`
+	Km    float64 `json:"KM" envconfig:"KM"`
+}
+
+// CustomFilter : registered custom filter name and its own parameters
+type CustomFilter struct {
+	Name   string          `json:"NAME" envconfig:"NAME"`
+	Params json.RawMessage `json:"PARAMS,omitempty" envconfig:"PARAMS"`
 }
 
 // TwitterList : twitter list to store the result
@@ -54,6 +241,12 @@ type FromToNumber struct {
 	To   int64 `json:"TO" envconfig:"TO"`
 }
 
+// FromToFloat : From-To-Float
+type FromToFloat struct {
+	From float64 `json:"FROM" envconfig:"FROM"`
+	To   float64 `json:"TO" envconfig:"TO"`
+}
+
 // FromToDate : From-To-Date
 type FromToDate struct {
 	From time.Time `json:"FROM" envconfig:"FROM"`
@@ -88,7 +281,8 @@ var (
 			TweetsCountBetween:    FromToNumber{From: 0, To: 100000},
 			ListsCountBetween:     FromToNumber{From: 0, To: 100000},
 			JoinedBetween:         FromToDate{From: time.Time{}, To: time.Now()},
-			Verified:              false,
+			Verified:              TriStateIgnore,
+			Protected:             TriStateExclude,
 		},
 		Following:                 true,
 		Followers:                 true,
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTriStateJSON(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected TriState
+	}{
+		// the old boolean values
+		{`true`, TriStateRequire},
+		{`false`, TriStateIgnore},
+		{`"REQUIRE"`, TriStateRequire},
+		{`"exclude"`, TriStateExclude},
+		{`" Ignore "`, TriStateIgnore},
+		{`"true"`, TriStateRequire},
+		{`""`, ""},
+	}
+	for _, tt := range tests {
+		var sc SearchCriteria
+		if err := json.Unmarshal([]byte(`{"VERIFIED": `+tt.data+`}`), &sc); err != nil {
+			t.Errorf("%v: %v", tt.data, err)
+			continue
+		}
+		if sc.Verified != tt.expected {
+			t.Errorf("%v: decoded %q, expected %q", tt.data, sc.Verified, tt.expected)
+		}
+	}
+
+	for _, data := range []string{`"MAYBE"`, `1`, `"requires"`, `{}`} {
+		var sc SearchCriteria
+		if err := json.Unmarshal([]byte(`{"PROTECTED": `+data+`}`), &sc); err == nil {
+			t.Errorf("%v: decoded %q, expected error", data, sc.Protected)
+		}
+	}
+}
+
+func TestTriStateDecode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected TriState
+	}{
+		{"true", TriStateRequire},
+		{"0", TriStateIgnore},
+		{"require", TriStateRequire},
+		{"Exclude", TriStateExclude},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		var s TriState
+		if err := s.Decode(tt.value); err != nil {
+			t.Errorf("%q: %v", tt.value, err)
+			continue
+		}
+		if s != tt.expected {
+			t.Errorf("%q: decoded %q, expected %q", tt.value, s, tt.expected)
+		}
+	}
+	var s TriState
+	if err := s.Decode("sometimes"); err == nil {
+		t.Errorf("sometimes decoded as %q", s)
+	}
+}
+
+func TestTriStateOr(t *testing.T) {
+	if s := TriState("").Or(TriStateExclude); s != TriStateExclude {
+		t.Errorf("not set state %q, expected the default", s)
+	}
+	if s := TriState("ignore").Or(TriStateExclude); s != TriStateIgnore {
+		t.Errorf("ignore state %q, expected %q", s, TriStateIgnore)
+	}
+	if s := defaultConfiguration.SearchCriteria.Protected; s != TriStateExclude {
+		t.Errorf("PROTECTED default %q, expected %q", s, TriStateExclude)
+	}
+}
+
+func TestSearchCriteriaValidate(t *testing.T) {
+	valid := []SearchCriteria{
+		{},
+		{Verified: "require", Protected: TriStateIgnore, GeoEnabled: "Exclude", MissingStatus: "include"},
+	}
+	for _, sc := range valid {
+		if err := sc.Validate(); err != nil {
+			t.Errorf("%+v: %v", sc, err)
+		}
+	}
+	invalid := []struct {
+		sc  SearchCriteria
+		err string
+	}{
+		{SearchCriteria{Verified: "yes please"}, "VERIFIED"},
+		{SearchCriteria{Protected: "maybe"}, "PROTECTED"},
+		{SearchCriteria{DefaultProfile: "on"}, "DEFAULT_PROFILE"},
+		{SearchCriteria{DefaultProfileImage: "x"}, "DEFAULT_PROFILE_IMAGE"},
+		{SearchCriteria{GeoEnabled: "x"}, "GEO_ENABLED"},
+		{SearchCriteria{MissingStatus: "sometimes"}, "MISSING_STATUS"},
+	}
+	for _, tt := range invalid {
+		err := tt.sc.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%+v: error %v, expected %v", tt.sc, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package eval
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"twfinder/finder"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// offline evaluation of the search criteria over already collected profiles,
+// one twitter user JSON object per line, e.g. the users/lookup responses or the stored results.
+
+// maxLineSize : the longest profile line
+const maxLineSize = 1024 * 1024
+
+// Result : the user profile and its match report
+type Result struct {
+	User   anaconda.User
+	Report finder.MatchReport
+}
+
+// FilterStat : how many profiles passed the criteria
+type FilterStat struct {
+	Filter string
+	Passed int
+	Total  int
+}
+
+// Rate : the passed percentage
+func (s FilterStat) Rate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Passed) * 100 / float64(s.Total)
+}
+
+// Summary : the evaluation results
+type Summary struct {
+	Matches    []Result
+	NonMatches []Result
+	Filters    []FilterStat
+}
+
+// EvaluateFile : run the finder over the profiles of the JSONL file
+func EvaluateFile(f *finder.Finder, path string) (Summary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Summary{}, err
+	}
+	defer file.Close()
+	return Evaluate(f, file)
+}
+
+// Evaluate : run the finder over every profile line
+func Evaluate(f *finder.Finder, r io.Reader) (Summary, error) {
+	names := f.FilterNames()
+	sum := Summary{Filters: make([]FilterStat, len(names))}
+	for i, name := range names {
+		sum.Filters[i].Filter = name
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		profile, err := finder.DecodeProfile([]byte(text))
+		if err != nil {
+			return sum, fmt.Errorf("line %v: invalid profile: %v", line, err)
+		}
+		user := profile.User
+		report := f.MatchProfile(&profile)
+		for i, res := range report.Filters {
+			sum.Filters[i].Total++
+			if res.Passed {
+				sum.Filters[i].Passed++
+			}
+		}
+		if report.Matched {
+			sum.Matches = append(sum.Matches, Result{User: user, Report: report})
+		} else {
+			sum.NonMatches = append(sum.NonMatches, Result{User: user, Report: report})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return sum, fmt.Errorf("line %v: %v", line+1, err)
+	}
+	return sum, nil
+}
+
+// Print : write the matches, the non-matches with their failed criteria and the pass rates
+func (s Summary) Print(w io.Writer) {
+	fmt.Fprintf(w, "MATCHES (%v)\n", len(s.Matches))
+	for _, r := range s.Matches {
+		fmt.Fprintf(w, "  @%v score:%v %v\n", r.User.ScreenName, r.Report.Score, passedFilters(r.Report, true))
+	}
+	fmt.Fprintf(w, "NON-MATCHES (%v)\n", len(s.NonMatches))
+	for _, r := range s.NonMatches {
+		fmt.Fprintf(w, "  @%v score:%v %v\n", r.User.ScreenName, r.Report.Score, passedFilters(r.Report, false))
+	}
+	total := len(s.Matches) + len(s.NonMatches)
+	fmt.Fprintf(w, "FILTERS PASS RATE (%v profiles)\n", total)
+	for _, f := range s.Filters {
+		fmt.Fprintf(w, "  %-30v %6.1f%% (%v/%v)\n", f.Filter, f.Rate(), f.Passed, f.Total)
+	}
+	if total > 0 {
+		fmt.Fprintf(w, "MATCH RATE %.1f%% (%v/%v)\n", float64(len(s.Matches))*100/float64(total), len(s.Matches), total)
+	}
+}
+
+// passedFilters : the filters results that passed or failed
+func passedFilters(report finder.MatchReport, passed bool) string {
+	parts := []string{}
+	for _, f := range report.Filters {
+		if f.Passed == passed {
+			parts = append(parts, f.String())
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,84 @@
+package eval
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"twfinder/config"
+	"twfinder/finder"
+	"twfinder/testutil"
+)
+
+func newTestFinder(t *testing.T) *finder.Finder {
+	t.Helper()
+	testutil.InitLogger()
+	f, err := finder.NewFinder(config.SearchCriteria{
+		SearchBioContext:      []string{"golang"},
+		FollowersCountBetween: config.FromToNumber{From: 0, To: 1000},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func screenNames(results []Result) []string {
+	names := []string{}
+	for _, r := range results {
+		names = append(names, r.User.ScreenName)
+	}
+	return names
+}
+
+func TestEvaluateFile(t *testing.T) {
+	sum, err := EvaluateFile(newTestFinder(t), "testdata/profiles.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(screenNames(sum.Matches), ","); got != "gopher" {
+		t.Errorf("matches %v, expected gopher", got)
+	}
+	if got := strings.Join(screenNames(sum.NonMatches), ","); got != "rustacean,famous_gopher,private_gopher,jsdev" {
+		t.Errorf("non-matches %v", got)
+	}
+
+	expected := []FilterStat{
+		{Filter: "PROTECTED", Passed: 4, Total: 5},
+		{Filter: "BIO", Passed: 3, Total: 5},
+		{Filter: "FOLLOWERS", Passed: 3, Total: 5},
+	}
+	if len(sum.Filters) != len(expected) {
+		t.Fatalf("filters %+v, expected %+v", sum.Filters, expected)
+	}
+	for i, e := range expected {
+		if sum.Filters[i] != e {
+			t.Errorf("filter %v: %+v, expected %+v", i, sum.Filters[i], e)
+		}
+	}
+	if rate := sum.Filters[1].Rate(); rate != 60 {
+		t.Errorf("BIO pass rate %v, expected 60", rate)
+	}
+
+	var out bytes.Buffer
+	sum.Print(&out)
+	for _, s := range []string{"MATCHES (1)", "NON-MATCHES (4)", "MATCH RATE 20.0% (1/5)"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("output without %q:\n%v", s, out.String())
+		}
+	}
+}
+
+func TestEvaluateInvalidLine(t *testing.T) {
+	input := `{"id": 1, "screen_name": "gopher", "description": "golang"}` + "\n" + `{"id": 2,` + "\n"
+	_, err := Evaluate(newTestFinder(t), strings.NewReader(input))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("error %v, expected the line 2 error", err)
+	}
+}
+
+func TestFilterStatRate(t *testing.T) {
+	if rate := (FilterStat{}).Rate(); rate != 0 {
+		t.Errorf("rate without profiles %v, expected 0", rate)
+	}
+}
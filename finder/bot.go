@@ -0,0 +1,168 @@
+package finder
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// offline bot and spam likelihood, computed from the users/lookup profile only,
+// every signal is scored from 0 (human like) to 1 (bot like) and the bot score is the weighted sum.
+
+// BotSignal : one signal of the bot score
+type BotSignal struct {
+	Name string `json:"NAME"`
+	// Value : the profile value the signal is based on
+	Value  string  `json:"VALUE"`
+	Score  float64 `json:"SCORE"`
+	Weight float64 `json:"WEIGHT"`
+}
+
+// BotScore : bot likelihood from 0 to 1 and the breakdown of its signals
+type BotScore struct {
+	Score   float64     `json:"SCORE"`
+	Signals []BotSignal `json:"SIGNALS"`
+}
+
+func (b BotScore) String() string {
+	return fmt.Sprintf("%v: %v", formatFloat(b.Score), b.breakdown())
+}
+
+// breakdown : the signals that added to the score
+func (b BotScore) breakdown() string {
+	parts := make([]string, 0, len(b.Signals))
+	for _, s := range b.Signals {
+		if s.Score > 0 {
+			parts = append(parts, fmt.Sprintf("%v %v (%v)", s.Name, s.Score, s.Value))
+		}
+	}
+	if len(parts) == 0 {
+		return "no signals"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// botSignals : the signals and their weights, the weights sum to 1
+var botSignals = []struct {
+	name   string
+	weight float64
+	score  func(u *anaconda.User, joined time.Time) (float64, string)
+}{
+	{"default_avatar", 0.2, botDefaultAvatar},
+	{"handle_digits", 0.15, botHandleDigits},
+	{"follow_asymmetry", 0.2, botFollowAsymmetry},
+	{"tweet_rate", 0.15, botTweetRate},
+	{"account_age", 0.15, botAccountAge},
+	{"empty_bio", 0.15, botEmptyBio},
+}
+
+// userBotScore : weighted sum of the bot signals of the user, computed once per Match
+func userBotScore(u *profile) BotScore {
+	return u.botOf()
+}
+
+// botScore : score the bot signals, created_at is parsed once
+// and the date based signals are not scored if it is missing or invalid.
+func botScore(u *anaconda.User) BotScore {
+	joined, err := time.Parse(time.RubyDate, u.CreatedAt)
+	if err != nil {
+		joined = time.Time{}
+	}
+	res := BotScore{Signals: make([]BotSignal, 0, len(botSignals))}
+	total := 0.0
+	for _, s := range botSignals {
+		score, value := s.score(u, joined)
+		score = round2(clamp01(score))
+		total += score * s.weight
+		res.Signals = append(res.Signals, BotSignal{Name: s.name, Value: value, Score: score, Weight: s.weight})
+	}
+	res.Score = round2(total)
+	return res
+}
+
+// userBotScoreValue : the bot score used by the bot criteria
+func userBotScoreValue(u *profile) float64 { return userBotScore(u).Score }
+
+// userBotDetail : the signals breakdown shown with the bot criteria result
+func userBotDetail(u *profile) string { return userBotScore(u).breakdown() }
+
+// botDefaultAvatar : the egg / default profile image
+func botDefaultAvatar(u *anaconda.User, _ time.Time) (float64, string) {
+	if u.DefaultProfileImage {
+		return 1, "default profile image"
+	}
+	return 0, "custom profile image"
+}
+
+// botHandleDigits : handles with many digits e.g. the suggested "john84736251",
+// a run of 5 digits or more at the end is a full signal.
+func botHandleDigits(u *anaconda.User, _ time.Time) (float64, string) {
+	handle := u.ScreenName
+	digits, trailing := 0, 0
+	for _, r := range handle {
+		if unicode.IsDigit(r) {
+			digits++
+			trailing++
+		} else {
+			trailing = 0
+		}
+	}
+	value := fmt.Sprintf("%v/%v digits", digits, len(handle))
+	if trailing >= 5 {
+		return 1, value
+	}
+	if len(handle) == 0 {
+		return 0, value
+	}
+	// up to 10% digits is common e.g. "dev1", 40% and more is a full signal
+	return (float64(digits)/float64(len(handle)) - 0.1) / 0.3, value
+}
+
+// botFollowAsymmetry : following many more accounts than the followers (follow-back bots),
+// accounts that follow less than 100 are not scored, following 20 times the followers is a full signal.
+func botFollowAsymmetry(u *anaconda.User, _ time.Time) (float64, string) {
+	value := fmt.Sprintf("following %v, followers %v", u.FriendsCount, u.FollowersCount)
+	if u.FriendsCount < 100 {
+		return 0, value
+	}
+	r := ratio(float64(u.FriendsCount), float64(u.FollowersCount))
+	if r <= 1 {
+		return 0, value
+	}
+	return math.Log10(r) / math.Log10(20), value
+}
+
+// botTweetRate : more than 20 tweets per day is suspicious, 100 and more is a full signal
+func botTweetRate(u *anaconda.User, joined time.Time) (float64, string) {
+	if joined.IsZero() {
+		return 0, "unknown"
+	}
+	rate := ratio(float64(u.StatusesCount), float64(daysSince(joined)))
+	return (rate - 20) / 80, strconv.FormatFloat(rate, 'f', 1, 64) + " tweets/day"
+}
+
+// botAccountAge : accounts younger than 30 days are a full signal, one year and older are not scored
+func botAccountAge(_ *anaconda.User, joined time.Time) (float64, string) {
+	if joined.IsZero() {
+		return 0, "unknown"
+	}
+	age := daysSince(joined)
+	return float64(365-age) / (365 - 30), fmt.Sprintf("%v days", age)
+}
+
+// botEmptyBio : no description
+func botEmptyBio(u *anaconda.User, _ time.Time) (float64, string) {
+	if strings.TrimSpace(u.Description) == "" {
+		return 1, "empty"
+	}
+	return 0, fmt.Sprintf("%v chars", len([]rune(u.Description)))
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
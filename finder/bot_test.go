@@ -0,0 +1,123 @@
+package finder
+
+import (
+	"sync"
+	"testing"
+	"twfinder/config"
+	"twfinder/logger"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// errorsLogger : count the logged errors
+type errorsLogger struct {
+	logger.ILogger
+	mtx    sync.Mutex
+	errors int
+}
+
+func (l *errorsLogger) Error(a ...interface{}) { l.count() }
+
+func (l *errorsLogger) Errorf(format string, prm ...interface{}) { l.count() }
+
+func (l *errorsLogger) count() {
+	l.mtx.Lock()
+	l.errors++
+	l.mtx.Unlock()
+}
+
+// recordErrors : count the errors logged until the end of the test
+func recordErrors(t *testing.T) *errorsLogger {
+	empty := logger.NewEmptyLogger()
+	logger.InitializeLogger(&empty)
+	l := logger.GetLogger()
+	old := *l
+	rec := &errorsLogger{ILogger: old}
+	*l = rec
+	t.Cleanup(func() { *l = old })
+	return rec
+}
+
+func botSignal(t *testing.T, b BotScore, name string) BotSignal {
+	t.Helper()
+	for _, s := range b.Signals {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("no %v signal in %v", name, b)
+	return BotSignal{}
+}
+
+func TestBotScoreWithoutCreatedAt(t *testing.T) {
+	rec := recordErrors(t)
+	for _, createdAt := range []string{"", "2007-05-23"} {
+		u := anaconda.User{ScreenName: "gopher", Description: "Go developer", StatusesCount: 5000, CreatedAt: createdAt}
+		b := botScore(&u)
+		for _, name := range []string{"tweet_rate", "account_age"} {
+			if s := botSignal(t, b, name); s.Score != 0 || s.Value != "unknown" {
+				t.Errorf("created_at %q: %v signal %+v, expected not scored", createdAt, name, s)
+			}
+		}
+		if b.Score != 0 {
+			t.Errorf("created_at %q: bot score %v, expected 0", createdAt, b)
+		}
+	}
+	if rec.errors != 0 {
+		t.Errorf("%v errors logged, expected none", rec.errors)
+	}
+}
+
+func TestBotScoreSignals(t *testing.T) {
+	u := anaconda.User{
+		ScreenName:          "john84736251",
+		DefaultProfileImage: true,
+		FriendsCount:        2000,
+		FollowersCount:      10,
+		StatusesCount:       100000,
+		CreatedAt:           "Wed May 23 06:01:13 +0000 2007",
+	}
+	b := botScore(&u)
+	expected := map[string]float64{"default_avatar": 1, "handle_digits": 1, "follow_asymmetry": 1, "tweet_rate": 0, "account_age": 0, "empty_bio": 1}
+	for name, score := range expected {
+		if s := botSignal(t, b, name); s.Score != score {
+			t.Errorf("%v signal %+v, expected score %v", name, s, score)
+		}
+	}
+	if b.Score != 0.7 {
+		t.Errorf("bot score %v, expected 0.7", b)
+	}
+}
+
+func TestMatchDerivedValuesOnDemand(t *testing.T) {
+	recordErrors(t)
+	u := anaconda.User{ScreenName: "gopher", Name: "Gopher", Description: "I write Go programs every day", Location: "Berlin"}
+
+	plain, err := NewFinder(config.SearchCriteria{SearchBioContext: []string{"go"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := plain.Match(&u)
+	if !report.Matched {
+		t.Fatalf("not matched: %v", report)
+	}
+	if report.Bot.Signals != nil || report.Language.Code != "" || report.Place.City != "" {
+		t.Errorf("derived values computed without criteria: %+v %+v %+v", report.Bot, report.Language, report.Place)
+	}
+	report.Describe(&u)
+	if len(report.Bot.Signals) != len(botSignals) || report.Place.City == "" {
+		t.Errorf("derived values not described: %+v %+v", report.Bot, report.Place)
+	}
+
+	bot, err := NewFinder(config.SearchCriteria{MaxBotScore: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report = bot.Match(&u)
+	if len(report.Bot.Signals) != len(botSignals) {
+		t.Errorf("bot score of the bot criteria not in the report: %+v", report.Bot)
+	}
+	if report.profile.bot == nil || report.profile.language != nil || report.profile.place != nil {
+		t.Errorf("derived values %+v, expected the bot score only", report.profile)
+	}
+}
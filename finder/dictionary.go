@@ -0,0 +1,246 @@
+package finder
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// keyword files, one keyword per line with the same keyword syntax,
+// '#' starts a comment line and '-' an exclusion e.g.
+//
+//	# companies
+//	acme corp
+//	word:CTO
+//	-recruiter
+//
+// substring, word and prefix keywords are matched in a single pass with an Aho-Corasick automaton,
+// the other modes (phrase, regex, fuzzy) are matched one by one.
+
+// filePrefix : text context entry that point to a keyword file e.g. "file:companies.txt"
+const filePrefix = "file:"
+
+// dictionary : compiled keywords of a keyword file
+type dictionary struct {
+	// source : the keyword file path
+	source   string
+	patterns []*matcher
+	// others : keywords that can't be matched by the automaton
+	others []*matcher
+	ac     *automaton
+	// weighted : the keywords have different weights, find the highest weight instead of the first match
+	weighted bool
+}
+
+func (d *dictionary) String() string {
+	return fmt.Sprintf("%v%q (%v keywords)", filePrefix, d.source, len(d.patterns)+len(d.others))
+}
+
+// match : the first (or the highest weight) keyword that match the text
+func (d *dictionary) match(text string) (*matcher, bool) {
+	var best *matcher
+	if len(d.patterns) > 0 {
+		folded := d.patterns[0].fold(text)
+		d.ac.scan(folded, func(idx, end int) bool {
+			m := d.patterns[idx]
+			start := end - len(m.keyword)
+			if !boundedAt(folded, start, end, m.mode) {
+				return true
+			}
+			if best == nil || m.weight > best.weight {
+				best = m
+			}
+			return d.weighted
+		})
+	}
+	for _, m := range d.others {
+		if (best == nil || (d.weighted && m.weight > best.weight)) && m.match(text) {
+			best = m
+		}
+	}
+	return best, best != nil
+}
+
+// boundedAt : check the word boundaries of the match [start, end) for the match mode
+func boundedAt(text string, start, end int, mode matchMode) bool {
+	if mode == substringMode {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	if start > 0 && isWordRune(before) {
+		return false
+	}
+	if mode == prefixMode {
+		return true
+	}
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	return end == len(text) || !isWordRune(after)
+}
+
+// loadKeywordFile : read the keywords and the exclusions of the file
+func loadKeywordFile(path string) ([]string, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	include, exclude := []string{}, []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "-"):
+			exclude = append(exclude, line[1:])
+		default:
+			include = append(include, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil, fmt.Errorf("no keywords in %q", path)
+	}
+	return include, exclude, nil
+}
+
+// newDictionary : compile the keywords with the automaton
+func newDictionary(source string, keywords []string, fold func(string) string) (*dictionary, error) {
+	d := &dictionary{source: source}
+	for i, keyword := range keywords {
+		m, err := parseKeyword(keyword, fold)
+		if err != nil {
+			return nil, fmt.Errorf("%v line %q: %v", source, keywords[i], err)
+		}
+		switch m.mode {
+		case substringMode, wordMode, prefixMode:
+			d.patterns = append(d.patterns, m)
+		default:
+			d.others = append(d.others, m)
+		}
+		if m.weight != 1 {
+			d.weighted = true
+		}
+	}
+	words := make([]string, 0, len(d.patterns))
+	for _, m := range d.patterns {
+		words = append(words, m.keyword)
+	}
+	d.ac = newAutomaton(words)
+	return d, nil
+}
+
+// fileExpression : the keywords of the file OR-ed and the exclusions negated
+func fileExpression(fieldName, path string, fold func(string) string) (node, error) {
+	include, exclude, err := loadKeywordFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred during load the keyword file: %v", err)
+	}
+	nodes := []node{}
+	if len(include) > 0 {
+		d, err := newDictionary(path, include, fold)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &dictionaryNode{field: fieldName, dict: d})
+	}
+	if len(exclude) > 0 {
+		d, err := newDictionary(path, exclude, fold)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &notNode{child: &dictionaryNode{field: fieldName, dict: d}})
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return &andNode{children: nodes}, nil
+}
+
+// automaton : Aho-Corasick automaton over the bytes of the keywords
+type automaton struct {
+	nodes []acNode
+}
+
+type acNode struct {
+	next map[byte]int
+	fail int
+	// output : indexes of the keywords that end at this node
+	output []int
+	// dictLink : the nearest node in the fail chain with output, -1 if none
+	dictLink int
+}
+
+func newAutomaton(keywords []string) *automaton {
+	a := &automaton{nodes: []acNode{{next: map[byte]int{}, dictLink: -1}}}
+	for i, k := range keywords {
+		cur := 0
+		for j := 0; j < len(k); j++ {
+			nxt, ok := a.nodes[cur].next[k[j]]
+			if !ok {
+				nxt = len(a.nodes)
+				a.nodes = append(a.nodes, acNode{next: map[byte]int{}, dictLink: -1})
+				a.nodes[cur].next[k[j]] = nxt
+			}
+			cur = nxt
+		}
+		a.nodes[cur].output = append(a.nodes[cur].output, i)
+	}
+	// breadth first to set the fail links
+	queue := []int{}
+	for _, child := range a.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for b, child := range a.nodes[cur].next {
+			f := a.nodes[cur].fail
+			for {
+				if nxt, ok := a.nodes[f].next[b]; ok && nxt != child {
+					a.nodes[child].fail = nxt
+					break
+				}
+				if f == 0 {
+					break
+				}
+				f = a.nodes[f].fail
+			}
+			fail := a.nodes[child].fail
+			if len(a.nodes[fail].output) > 0 {
+				a.nodes[child].dictLink = fail
+			} else {
+				a.nodes[child].dictLink = a.nodes[fail].dictLink
+			}
+			queue = append(queue, child)
+		}
+	}
+	return a
+}
+
+// scan : call found with the keyword index and the end of every match, until found return false
+func (a *automaton) scan(text string, found func(idx, end int) bool) {
+	cur := 0
+	for i := 0; i < len(text); i++ {
+		for {
+			if nxt, ok := a.nodes[cur].next[text[i]]; ok {
+				cur = nxt
+				break
+			}
+			if cur == 0 {
+				break
+			}
+			cur = a.nodes[cur].fail
+		}
+		for n := cur; n > 0; n = a.nodes[n].dictLink {
+			for _, out := range a.nodes[n].output {
+				if !found(out, i+1) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package finder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// benchmarkKeywords : 2000 generated company names and job titles
+func benchmarkKeywords() []string {
+	prefixes := []string{"acme", "globex", "initech", "umbrella", "hooli", "stark", "wayne", "wonka", "tyrell", "cyberdyne"}
+	suffixes := []string{"labs", "systems", "group", "corp", "digital", "studio", "works", "capital", "partners", "health"}
+	keywords := []string{}
+	for i := 0; len(keywords) < 2000; i++ {
+		keywords = append(keywords, fmt.Sprintf("%v%v %v", prefixes[i%len(prefixes)], i, suffixes[(i/len(prefixes))%len(suffixes)]))
+	}
+	return keywords
+}
+
+var benchmarkBios = []string{
+	"Software engineer, coffee lover and runner. Opinions are my own.",
+	"Head of product at Globex1503 Systems, previously at a bank",
+	"Father of two, writing about technology, startups and design",
+	"Director of engineering | building the future of work @ remote",
+	"Designer at Wonka7 Studio. I love typography and long walks.",
+}
+
+func BenchmarkDictionary(b *testing.B) {
+	d, err := newDictionary("benchmark", benchmarkKeywords(), normalize)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.match(benchmarkBios[i%len(benchmarkBios)])
+	}
+}
+
+// BenchmarkKeywordLoop : the keywords matched one by one, as the text contexts do
+func BenchmarkKeywordLoop(b *testing.B) {
+	matchers := []*matcher{}
+	for _, k := range benchmarkKeywords() {
+		m, err := parseKeyword(k, normalize)
+		if err != nil {
+			b.Fatal(err)
+		}
+		matchers = append(matchers, m)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		text := benchmarkBios[i%len(benchmarkBios)]
+		for _, m := range matchers {
+			if m.match(text) {
+				break
+			}
+		}
+	}
+}
+
+func TestDictionaryMatchesKeywordLoop(t *testing.T) {
+	keywords := benchmarkKeywords()
+	d, err := newDictionary("benchmark", keywords, normalize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bio := range benchmarkBios {
+		expected := ""
+		for _, k := range keywords {
+			if strings.Contains(normalize(bio), k) {
+				expected = k
+				break
+			}
+		}
+		found := ""
+		if m, ok := d.match(bio); ok {
+			found = m.keyword
+		}
+		if (expected == "") != (found == "") {
+			t.Errorf("bio %q: expected match %q, found %q", bio, expected, found)
+		}
+	}
+}
+
+// writeKeywordFile : keyword file in a temp directory
+func writeKeywordFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keywords.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBoundedAt(t *testing.T) {
+	tests := []struct {
+		text    string
+		keyword string
+		mode    matchMode
+		bounded bool
+	}{
+		{"i write go daily", "go", wordMode, true},
+		{"go", "go", wordMode, true},
+		{"golang", "go", wordMode, false},
+		{"ergo", "go", wordMode, false},
+		{"go_lang", "go", wordMode, false},
+		{"go-lang", "go", wordMode, true},
+		{"café", "caf", wordMode, false},
+		{"devops", "dev", prefixMode, true},
+		{"webdev", "dev", prefixMode, false},
+		{"web dev", "dev", prefixMode, true},
+		{"ergo", "go", substringMode, true},
+	}
+	for _, tt := range tests {
+		start := strings.Index(tt.text, tt.keyword)
+		if bounded := boundedAt(tt.text, start, start+len(tt.keyword), tt.mode); bounded != tt.bounded {
+			t.Errorf("%v:%v in %q bounded %v, expected %v", tt.mode, tt.keyword, tt.text, bounded, tt.bounded)
+		}
+	}
+}
+
+func TestDictionaryMatchedKeyword(t *testing.T) {
+	d, err := newDictionary("test", []string{"acme", "word:go", "prefix:dev", `"open source"`, `regex:\bCTO\b`}, normalize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text    string
+		keyword string
+	}{
+		{"Engineer at ACME Corp", `"acme"`},
+		{"I write Go daily", `word:"go"`},
+		{"Golang developer", `prefix:"dev"`},
+		{"Open  Source maintainer", `phrase:"open source"`},
+		{"CTO of a startup", `regex:"\\bCTO\\b"`},
+		{"Golang webdev, director", ""},
+	}
+	for _, tt := range tests {
+		found := ""
+		if m, ok := d.match(tt.text); ok {
+			found = m.String()
+		}
+		if found != tt.keyword {
+			t.Errorf("%q matched %v, expected %v", tt.text, found, tt.keyword)
+		}
+	}
+}
+
+func TestDictionaryWeighted(t *testing.T) {
+	tests := []struct {
+		keywords []string
+		text     string
+		keyword  string
+	}{
+		// the first match without weights
+		{[]string{"golang", "go"}, "golang", `"go"`},
+		{[]string{"word:rust", "word:go"}, "go and rust", `word:"go"`},
+		// the highest weight
+		{[]string{"go", "golang^3", "word:gopher^2"}, "gopher, golang", `"golang"^3`},
+		{[]string{"go", "word:gopher^2"}, "gopher, golang", `word:"gopher"^2`},
+		{[]string{"go^2", `"open source"^5`}, "go open source", `phrase:"open source"^5`},
+		{[]string{"go^2", `"open source"^0.5`}, "go open source", `"go"^2`},
+	}
+	for _, tt := range tests {
+		d, err := newDictionary("test", tt.keywords, normalize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := ""
+		if m, ok := d.match(tt.text); ok {
+			found = m.String()
+		}
+		if found != tt.keyword {
+			t.Errorf("%v in %q matched %v, expected %v", tt.keywords, tt.text, found, tt.keyword)
+		}
+	}
+}
+
+func TestLoadKeywordFile(t *testing.T) {
+	path := writeKeywordFile(t, "# companies\nacme corp\n\n  word:CTO  \n-recruiter\n# -not an exclusion\n-word:hiring\n")
+	include, exclude, err := loadKeywordFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(include) != "[acme corp word:CTO]" {
+		t.Errorf("keywords %q", include)
+	}
+	if fmt.Sprint(exclude) != "[recruiter word:hiring]" {
+		t.Errorf("exclusions %q", exclude)
+	}
+
+	for _, content := range []string{"", "\n\n", "# comments only\n"} {
+		if _, _, err := loadKeywordFile(writeKeywordFile(t, content)); err == nil || !strings.Contains(err.Error(), "no keywords") {
+			t.Errorf("file %q: error %v, expected no keywords", content, err)
+		}
+	}
+	if _, _, err := loadKeywordFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("missing file loaded")
+	}
+}
+
+func TestKeywordFileContext(t *testing.T) {
+	recordErrors(t)
+	path := writeKeywordFile(t, "# companies\nacme\nword:CTO^2\n-recruiter\n")
+	tests := []struct {
+		bio     string
+		matched bool
+		decider string
+	}{
+		{"CTO at Acme", true, `word:"cto"^2`},
+		{"Engineer at Acme", true, `"acme"`},
+		{"Acme recruiter", false, `NOT "recruiter"`},
+		{"Director at Globex", false, `file:"` + path},
+	}
+	for _, criteria := range []config.SearchCriteria{
+		{SearchBioContext: []string{filePrefix + path}},
+		{Query: fmt.Sprintf("bio:file:%q", path)},
+	} {
+		f, err := NewFinder(criteria)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range tests {
+			u := anaconda.User{Description: tt.bio}
+			report := f.Match(&u)
+			if report.Matched != tt.matched {
+				t.Errorf("%q matched %v, expected %v: %v", tt.bio, report.Matched, tt.matched, report)
+			}
+			deciders := []string{}
+			for _, r := range report.Filters {
+				deciders = append(deciders, r.Decider)
+			}
+			if got := strings.Join(deciders, " | "); !strings.Contains(got, tt.decider) {
+				t.Errorf("%q decided by %v, expected %v", tt.bio, got, tt.decider)
+			}
+		}
+	}
+
+	if _, err := NewFinder(config.SearchCriteria{SearchBioContext: []string{filePrefix + writeKeywordFile(t, "")}}); err == nil {
+		t.Error("empty keyword file accepted")
+	}
+	if _, err := NewFinder(config.SearchCriteria{SearchBioContext: []string{filePrefix + writeKeywordFile(t, "regex:go(")}}); err == nil {
+		t.Error("keyword file with invalid regex accepted")
+	}
+}
@@ -0,0 +1,177 @@
+package finder
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// entityKind : how the keyword is compared with the profile entities
+type entityKind int
+
+const (
+	// tagEntity : mentions, hashtags and cashtags, compared without the sigil
+	tagEntity entityKind = iota
+	// domainEntity : urls, compared by domain (and sub-domains)
+	domainEntity
+	// emailEntity : full address if the keyword has '@', otherwise the domain
+	emailEntity
+)
+
+var (
+	mentionRegex = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@/])[@＠]([A-Za-z0-9_]{1,15})`)
+	hashtagRegex = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_#&/])[#＃]([\p{L}\p{M}\p{N}_]*[\p{L}\p{M}][\p{L}\p{M}\p{N}_]*)`)
+	cashtagRegex = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_$])\$([A-Za-z]{1,6}(?:[._][A-Za-z]{1,2})?)\b`)
+	emailRegex   = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+	urlRegex     = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+)
+
+// entityKeyword : keyword matched against the profile entities
+type entityKeyword struct {
+	kind entityKind
+	// keyword : folded keyword, "*" match any entity
+	keyword string
+	fold    func(string) string
+}
+
+// newEntityKeyword : build entity keyword e.g. "@company", "#golang", "github.com", "*"
+func newEntityKeyword(kind entityKind, keyword string, fold func(string) string) (*entityKeyword, error) {
+	k := &entityKeyword{kind: kind, fold: fold}
+	keyword = strings.TrimSpace(keyword)
+	switch kind {
+	case tagEntity:
+		k.keyword = fold(strings.TrimLeft(keyword, "@＠#＃$"))
+	case domainEntity:
+		k.keyword = urlDomain(keyword)
+	case emailEntity:
+		k.keyword = strings.ToLower(keyword)
+		if !strings.Contains(keyword, "@") {
+			k.keyword = urlDomain(keyword)
+		}
+	}
+	if k.keyword == "" {
+		return nil, fmt.Errorf("empty entity keyword %q", keyword)
+	}
+	return k, nil
+}
+
+// match : the first entity value that match the keyword
+func (k *entityKeyword) match(values []string) (string, bool) {
+	for _, v := range values {
+		if k.keyword == "*" {
+			return v, true
+		}
+		switch k.kind {
+		case tagEntity:
+			if k.fold(v) == k.keyword {
+				return v, true
+			}
+		case domainEntity:
+			if matchDomain(v, k.keyword) {
+				return v, true
+			}
+		case emailEntity:
+			if strings.Contains(k.keyword, "@") {
+				if strings.EqualFold(v, k.keyword) {
+					return v, true
+				}
+			} else if matchDomain(v[strings.LastIndexByte(v, '@')+1:], k.keyword) {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (k *entityKeyword) String() string {
+	return strconv.Quote(k.keyword)
+}
+
+// urlDomain : lower case host of the url without "www.", the scheme is optional
+func urlDomain(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// matchDomain : host is the domain or one of its sub-domains
+func matchDomain(host, domain string) bool {
+	host = strings.ToLower(host)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// userProfileDomains : domains of the expanded profile urls
+func userProfileDomains(u *profile) []string {
+	res := []string{}
+	for _, e := range u.Entities.Url.Urls {
+		link := e.Expanded_url
+		if link == "" {
+			link = e.Url
+		}
+		if d := urlDomain(link); d != "" {
+			res = append(res, d)
+		}
+	}
+	if len(res) == 0 && u.URL != "" {
+		if d := urlDomain(u.URL); d != "" {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// userBioDomains : domains of the urls in the description,
+// shortened t.co links are replaced with the expanded url of the bio links and the profile url.
+func userBioDomains(u *profile) []string {
+	expanded := map[string]string{}
+	for _, e := range u.bioURLs {
+		expanded[e.URL] = e.ExpandedURL
+	}
+	for _, e := range u.Entities.Url.Urls {
+		expanded[e.Url] = e.Expanded_url
+	}
+	res := []string{}
+	for _, link := range urlRegex.FindAllString(u.Description, -1) {
+		link = strings.TrimRight(link, ".,;:!?)")
+		if e, ok := expanded[link]; ok && e != "" {
+			link = e
+		}
+		if d := urlDomain(link); d != "" {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+func userBioMentions(u *profile) []string {
+	return submatches(mentionRegex, u.Description)
+}
+
+func userBioHashtags(u *profile) []string {
+	return submatches(hashtagRegex, u.Description)
+}
+
+func userBioCashtags(u *profile) []string {
+	return submatches(cashtagRegex, u.Description)
+}
+
+func userBioEmails(u *profile) []string {
+	return emailRegex.FindAllString(u.Description, -1)
+}
+
+// submatches : the first group of every match
+func submatches(re *regexp.Regexp, text string) []string {
+	res := []string{}
+	for _, m := range re.FindAllStringSubmatch(text, -1) {
+		res = append(res, m[1])
+	}
+	return res
+}
@@ -0,0 +1,146 @@
+package finder
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"twfinder/config"
+	"twfinder/testutil"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// lookupUserPayload : users/lookup user, the bio and the profile have different t.co links
+const lookupUserPayload = `{
+  "id": 6253282,
+  "id_str": "6253282",
+  "name": "Go Team",
+  "screen_name": "goteam",
+  "location": "San Francisco, CA",
+  "description": "Building Go. Code at https://t.co/Ab12Cd34Ef and docs https://t.co/Zz98Yy76Xx.",
+  "url": "https://t.co/Pr0f1leUrl",
+  "entities": {
+    "url": {
+      "urls": [
+        {"url": "https://t.co/Pr0f1leUrl", "expanded_url": "https://go.dev", "display_url": "go.dev", "indices": [0, 23]}
+      ]
+    },
+    "description": {
+      "urls": [
+        {"url": "https://t.co/Ab12Cd34Ef", "expanded_url": "https://github.com/golang", "display_url": "github.com/golang", "indices": [21, 44]},
+        {"url": "https://t.co/Zz98Yy76Xx", "expanded_url": "https://pkg.go.dev/std", "display_url": "pkg.go.dev/std", "indices": [54, 77]}
+      ]
+    }
+  },
+  "protected": false,
+  "followers_count": 1200,
+  "friends_count": 30,
+  "listed_count": 10,
+  "created_at": "Wed May 23 06:01:13 +0000 2007",
+  "favourites_count": 5,
+  "verified": false,
+  "statuses_count": 3400,
+  "default_profile": false,
+  "default_profile_image": false
+}`
+
+func decodeLookupUser(t *testing.T) Profile {
+	t.Helper()
+	p, err := DecodeProfile([]byte(lookupUserPayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDecodeProfile(t *testing.T) {
+	p := decodeLookupUser(t)
+	expected := []BioURL{
+		{URL: "https://t.co/Ab12Cd34Ef", ExpandedURL: "https://github.com/golang"},
+		{URL: "https://t.co/Zz98Yy76Xx", ExpandedURL: "https://pkg.go.dev/std"},
+	}
+	if fmt.Sprint(p.BioURLs) != fmt.Sprint(expected) {
+		t.Errorf("bio urls %v, expected %v", p.BioURLs, expected)
+	}
+	if p.Id != 6253282 || p.ScreenName != "goteam" {
+		t.Errorf("user %v @%v, expected 6253282 @goteam", p.Id, p.ScreenName)
+	}
+	// the tweets entities are not used for the bio links
+	if len(p.Entities.Urls) != 0 {
+		t.Errorf("entities urls %v, expected none", p.Entities.Urls)
+	}
+
+	// the stored results keep the bio links in BIO_URLS
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := DecodeProfile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(stored.BioURLs) != fmt.Sprint(expected) {
+		t.Errorf("stored bio urls %v, expected %v", stored.BioURLs, expected)
+	}
+
+	profiles, err := DecodeProfiles([]byte("[" + lookupUserPayload + `, {"id": 2, "description": "no links"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || len(profiles[0].BioURLs) != 2 || len(profiles[1].BioURLs) != 0 {
+		t.Errorf("profiles %+v, expected 2 with the bio links of the first", profiles)
+	}
+	if _, err := DecodeProfile([]byte(`{"id": "x"}`)); err == nil {
+		t.Error("invalid profile decoded")
+	}
+}
+
+func TestUserBioDomainsExpandsDescriptionLinks(t *testing.T) {
+	p := decodeLookupUser(t)
+	u := newProfile(&p.User, p.BioURLs)
+	got := userBioDomains(u)
+	expected := []string{"github.com", "pkg.go.dev"}
+	if len(got) != len(expected) {
+		t.Fatalf("bio domains %v, expected %v", got, expected)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("bio domains %v, expected %v", got, expected)
+		}
+	}
+	if got := userProfileDomains(u); len(got) != 1 || got[0] != "go.dev" {
+		t.Errorf("profile domains %v, expected [go.dev]", got)
+	}
+}
+
+func TestBioURLDomainsMatchLookupPayload(t *testing.T) {
+	testutil.InitLogger()
+	tests := []struct {
+		domains []string
+		matched bool
+	}{
+		{[]string{"github.com"}, true},
+		{[]string{"go.dev"}, true}, // sub-domain pkg.go.dev
+		{[]string{"t.co"}, false},
+		{[]string{"gitlab.com"}, false},
+	}
+	p := decodeLookupUser(t)
+	for _, tt := range tests {
+		f, err := NewFinder(config.SearchCriteria{BioURLDomains: tt.domains})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report := f.MatchProfile(&p); report.Matched != tt.matched {
+			t.Errorf("BIO_URL_DOMAINS %v matched %v, expected %v: %v", tt.domains, report.Matched, tt.matched, report)
+		}
+	}
+
+	// anaconda.User alone drops entities.description, the bio links stay t.co
+	var plain anaconda.User
+	if err := json.Unmarshal([]byte(lookupUserPayload), &plain); err != nil {
+		t.Fatal(err)
+	}
+	if got := userBioDomains(newProfile(&plain, nil)); len(got) != 2 || got[0] != "t.co" {
+		t.Errorf("bio domains without the description entities %v, expected t.co", got)
+	}
+}
@@ -0,0 +1,687 @@
+package finder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"twfinder/config"
+)
+
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	numberField
+	floatField
+	dateField
+	boolField
+	entityField
+	languageField
+	countryField
+	nearField
+)
+
+// field : user profile field that can be used in the search criteria
+type field struct {
+	name   string
+	kind   fieldKind
+	text   func(*profile) string
+	number func(*profile) int64
+	float  func(*profile) float64
+	date   func(*profile) time.Time
+	flag   func(*profile) bool
+	// entities : values found in the profile, compared as entity
+	entities func(*profile) []string
+	entity   entityKind
+	// language : detected language of the profile
+	language func(*profile) Language
+	// place : resolved location of the profile
+	place func(*profile) Place
+	// fuzzy : the fuzzy match mode is allowed
+	fuzzy bool
+	// present : check if the field is available for the user, nil if always available
+	present func(*profile) bool
+	// detail : explanation appended to the result value e.g. the bot signals breakdown
+	detail func(*profile) string
+}
+
+// fields : available fields in the query language (field:value)
+var fields = map[string]field{
+	"handle":    {name: "Handle", kind: textField, text: userHandle, fuzzy: true},
+	"name":      {name: "Name", kind: textField, text: userName, fuzzy: true},
+	"bio":       {name: "BIO", kind: textField, text: userBio},
+	"location":  {name: "LOCATION", kind: textField, text: userLocation},
+	"followers": {name: "FOLLOWERS", kind: numberField, number: userFollowers},
+	"following": {name: "FOLLOWING", kind: numberField, number: userFollowing},
+	"likes":     {name: "LIKES", kind: numberField, number: userLikes},
+	"tweets":    {name: "TWEETS", kind: numberField, number: userTweets},
+	"lists":     {name: "LISTS", kind: numberField, number: userLists},
+	"joined":    {name: "JOINED", kind: dateField, date: userJoined},
+	"verified":  {name: "VERIFIED", kind: boolField, flag: userVerified},
+	"protected": {name: "PROTECTED", kind: boolField, flag: userProtected},
+	// profile flags
+	"default_profile":       {name: "DEFAULT_PROFILE", kind: boolField, flag: userDefaultProfile},
+	"default_profile_image": {name: "DEFAULT_PROFILE_IMAGE", kind: boolField, flag: userDefaultProfileImage},
+	"geo_enabled":           {name: "GEO_ENABLED", kind: boolField, flag: userGeoEnabled},
+	// profile url and bio entities
+	"url":     {name: "PROFILE_URL", kind: entityField, entities: userProfileDomains, entity: domainEntity},
+	"bio_url": {name: "BIO_URL", kind: entityField, entities: userBioDomains, entity: domainEntity},
+	"mention": {name: "BIO_MENTION", kind: entityField, entities: userBioMentions, entity: tagEntity},
+	"hashtag": {name: "BIO_HASHTAG", kind: entityField, entities: userBioHashtags, entity: tagEntity},
+	"cashtag": {name: "BIO_CASHTAG", kind: entityField, entities: userBioCashtags, entity: tagEntity},
+	"email":   {name: "BIO_EMAIL", kind: entityField, entities: userBioEmails, entity: emailEntity},
+	// last activity, based on the most recent status
+	"last_tweet":    {name: "LAST_TWEET", kind: dateField, date: userLastTweet, present: userHasStatus},
+	"inactive_days": {name: "INACTIVE_DAYS", kind: numberField, number: userInactiveDays, present: userHasStatus},
+	// detected language of the bio and name
+	"lang": {name: "LANGUAGE", kind: languageField, language: userLanguage},
+	// resolved location
+	"country": {name: "COUNTRY", kind: countryField, place: userPlace},
+	"near":    {name: "NEAR", kind: nearField, place: userPlace},
+	// derived metrics
+	"ratio":           {name: "FOLLOWERS_RATIO", kind: floatField, float: userFollowersRatio},
+	"age":             {name: "ACCOUNT_AGE_DAYS", kind: numberField, number: userAccountAge},
+	"tweets_per_day":  {name: "TWEETS_PER_DAY", kind: floatField, float: userTweetsPerDay},
+	"likes_per_tweet": {name: "LIKES_PER_TWEET", kind: floatField, float: userLikesPerTweet},
+	"listed_per_1k":   {name: "LISTED_PER_1K_FOLLOWERS", kind: floatField, float: userListedPer1KFollowers},
+	// bot and spam likelihood from 0 to 1
+	"bot": {name: "BOT_SCORE", kind: floatField, float: userBotScoreValue, detail: userBotDetail},
+}
+
+// node : search criteria expression tree
+type node interface {
+	String() string
+}
+
+type andNode struct{ children []node }
+type orNode struct{ children []node }
+type notNode struct{ child node }
+
+type textNode struct {
+	field   string
+	keyword *matcher
+}
+
+type entityNode struct {
+	field   string
+	keyword *entityKeyword
+}
+
+type numberNode struct {
+	field   string
+	between config.FromToNumber
+}
+
+type floatNode struct {
+	field   string
+	between config.FromToFloat
+}
+
+type dateNode struct {
+	field   string
+	between config.FromToDate
+}
+
+type boolNode struct {
+	field string
+	value bool
+}
+
+// dictionaryNode : any keyword of the keyword file
+type dictionaryNode struct {
+	field string
+	dict  *dictionary
+}
+
+type countryNode struct {
+	field string
+	// code : ISO 3166-1 alpha-2 country code
+	code string
+}
+
+// nearNode : the user location is within km of the center
+type nearNode struct {
+	field  string
+	center Place
+	km     float64
+}
+
+type languageNode struct {
+	field string
+	// code : ISO 639-1 language code
+	code string
+}
+
+func (n *andNode) String() string { return joinNodes(n.children, " AND ") }
+func (n *orNode) String() string  { return joinNodes(n.children, " OR ") }
+func (n *notNode) String() string { return "NOT " + n.child.String() }
+
+func (n *textNode) String() string {
+	return fmt.Sprintf("%v:%v", n.field, n.keyword)
+}
+
+func (n *entityNode) String() string {
+	return fmt.Sprintf("%v:%v", n.field, n.keyword)
+}
+
+func (n *numberNode) String() string {
+	return fmt.Sprintf("%v:%v", n.field, formatRange(n.between.From, n.between.To, 0, func(v int64) string {
+		return strconv.FormatInt(v, 10)
+	}))
+}
+
+func (n *floatNode) String() string {
+	return fmt.Sprintf("%v:%v", n.field, formatRange(n.between.From, n.between.To, 0, formatFloat))
+}
+
+func (n *dateNode) String() string {
+	return fmt.Sprintf("%v:%v", n.field, formatRange(n.between.From, n.between.To, time.Time{}, func(v time.Time) string {
+		return v.Format(dateLayout)
+	}))
+}
+
+func (n *boolNode) String() string {
+	return fmt.Sprintf("%v:%v", n.field, n.value)
+}
+
+func (n *dictionaryNode) String() string {
+	return fmt.Sprintf("%v:%v", n.field, n.dict)
+}
+
+func (n *countryNode) String() string {
+	return fmt.Sprintf("%v:%v", n.field, n.code)
+}
+
+func (n *nearNode) String() string {
+	return fmt.Sprintf("%v:%q~%v", n.field, n.center.City, formatFloat(n.km))
+}
+
+func (n *languageNode) String() string {
+	return fmt.Sprintf("%v:%v", n.field, n.code)
+}
+
+func joinNodes(nodes []node, sep string) string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		parts = append(parts, n.String())
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+func formatRange[T comparable](from, to, zero T, format func(T) string) string {
+	res := ".."
+	if from != zero {
+		res = format(from) + res
+	}
+	if to != zero {
+		res = res + format(to)
+	}
+	return res
+}
+
+// buildExpression : translate the search criteria into one expression tree
+// - any sub-route under the criteria is an AND condition
+// - any keyword in the text contexts is an OR condition, '-keyword' excludes
+// - QUERY is parsed and combined with the rest using AND
+// - keywords are normalized unless DISABLE_NORMALIZATION is set
+func buildExpression(sc config.SearchCriteria) (node, error) {
+	fold := normalize
+	if sc.DisableNormalization {
+		fold = strings.ToLower
+	}
+
+	expr := &andNode{}
+	add := func(n node) {
+		if n != nil {
+			expr.children = append(expr.children, n)
+		}
+	}
+
+	contexts := []struct {
+		field    string
+		keywords []string
+	}{
+		{"handle", sc.SearchHandleContext},
+		{"name", sc.SearchNameContext},
+		{"bio", sc.SearchBioContext},
+		{"location", sc.SearchLocationContext},
+		{"url", sc.ProfileURLDomains},
+		{"bio_url", sc.BioURLDomains},
+		{"mention", sc.BioMentions},
+		{"hashtag", sc.BioHashtags},
+		{"cashtag", sc.BioCashtags},
+		{"email", sc.BioEmails},
+	}
+	for _, c := range contexts {
+		n, err := contextExpression(c.field, c.keywords, fold)
+		if err != nil {
+			return nil, fmt.Errorf("error occurred during build the %v context: %v", c.field, err)
+		}
+		add(n)
+	}
+	add(numberExpression("followers", sc.FollowersCountBetween))
+	add(numberExpression("following", sc.FollowingCountBetween))
+	add(numberExpression("likes", sc.LikesCountBetween))
+	add(numberExpression("tweets", sc.TweetsCountBetween))
+	add(numberExpression("lists", sc.ListsCountBetween))
+	add(floatExpression("ratio", sc.FollowersRatioBetween))
+	add(numberExpression("age", sc.AccountAgeDaysBetween))
+	add(floatExpression("tweets_per_day", sc.TweetsPerDayBetween))
+	add(floatExpression("likes_per_tweet", sc.LikesPerTweetBetween))
+	add(floatExpression("listed_per_1k", sc.ListedPer1KFollowersBetween))
+	if sc.MaxBotScore > 0 {
+		// bot score below the maximum (To is exclusive)
+		add(&floatNode{field: "bot", between: config.FromToFloat{To: sc.MaxBotScore}})
+	}
+	if !sc.JoinedBetween.From.IsZero() || !sc.JoinedBetween.To.IsZero() {
+		add(&dateNode{field: "joined", between: sc.JoinedBetween})
+	}
+	if !sc.LastTweetedBetween.From.IsZero() || !sc.LastTweetedBetween.To.IsZero() {
+		add(&dateNode{field: "last_tweet", between: sc.LastTweetedBetween})
+	}
+	if sc.ExcludeInactiveDays > 0 {
+		// inactive for more than N days is excluded (To is exclusive)
+		add(&numberNode{field: "inactive_days", between: config.FromToNumber{To: sc.ExcludeInactiveDays + 1}})
+	}
+	flags := []struct {
+		field string
+		state config.TriState
+	}{
+		{"verified", sc.Verified},
+		{"default_profile", sc.DefaultProfile},
+		{"default_profile_image", sc.DefaultProfileImage},
+		{"geo_enabled", sc.GeoEnabled},
+	}
+	for _, f := range flags {
+		add(flagExpression(f.field, f.state))
+	}
+
+	n, err := languageExpression(sc.Languages)
+	if err != nil {
+		return nil, err
+	}
+	add(n)
+	if n, err = countryExpression(sc.LocationCountries); err != nil {
+		return nil, err
+	}
+	add(n)
+	if strings.TrimSpace(sc.LocationNear.Place) != "" {
+		n, err := newNearNode("near", sc.LocationNear.Place, sc.LocationNear.Km)
+		if err != nil {
+			return nil, fmt.Errorf("LOCATION_NEAR: %v", err)
+		}
+		add(n)
+	}
+
+	if strings.TrimSpace(sc.Query) != "" {
+		q, err := parseQuery(sc.Query, fold)
+		if err != nil {
+			return nil, fmt.Errorf("error occurred during parse the search query %q: %v", sc.Query, err)
+		}
+		// every top level AND condition in the query is a separate filter
+		if and, ok := q.(*andNode); ok {
+			expr.children = append(expr.children, and.children...)
+		} else {
+			add(q)
+		}
+	}
+	return expr, nil
+}
+
+// contextExpression : (keyword OR keyword ...) AND NOT (-keyword OR -keyword ...)
+// a keyword that starts with a literal dash can be escaped as \-keyword
+func contextExpression(fieldName string, keywords []string, fold func(string) string) (node, error) {
+	include := &orNode{}
+	exclude := &orNode{}
+	for _, keyword := range keywords {
+		if strings.TrimSpace(keyword) == "" {
+			continue
+		}
+		target := include
+		if strings.HasPrefix(keyword, "-") {
+			target = exclude
+			keyword = keyword[1:]
+		}
+		n, err := keywordNode(fieldName, keyword, fold)
+		if err != nil {
+			return nil, err
+		}
+		target.children = append(target.children, n)
+	}
+
+	switch {
+	case len(include.children) == 0 && len(exclude.children) == 0:
+		return nil, nil
+	case len(exclude.children) == 0:
+		return include, nil
+	case len(include.children) == 0:
+		return &notNode{child: exclude}, nil
+	}
+	return &andNode{children: []node{include, &notNode{child: exclude}}}, nil
+}
+
+// keywordNode : text or entity keyword node of the field
+func keywordNode(fieldName string, keyword string, fold func(string) string) (node, error) {
+	f := fields[fieldName]
+	if f.kind == entityField {
+		k, err := newEntityKeyword(f.entity, keyword, fold)
+		if err != nil {
+			return nil, err
+		}
+		return &entityNode{field: fieldName, keyword: k}, nil
+	}
+	if strings.HasPrefix(keyword, filePrefix) {
+		return fileExpression(fieldName, strings.TrimPrefix(keyword, filePrefix), fold)
+	}
+	m, err := parseKeyword(keyword, fold)
+	if err != nil {
+		return nil, err
+	}
+	return newTextNode(fieldName, m)
+}
+
+// newTextNode : check the match mode is allowed for the field
+func newTextNode(fieldName string, m *matcher) (node, error) {
+	if m.mode == fuzzyMode && !fields[fieldName].fuzzy {
+		return nil, fmt.Errorf("%v match mode is available for handle and name only", m.mode)
+	}
+	return &textNode{field: fieldName, keyword: m}, nil
+}
+
+func numberExpression(fieldName string, between config.FromToNumber) node {
+	if between.From > 0 || between.To > 0 {
+		return &numberNode{field: fieldName, between: between}
+	}
+	return nil
+}
+
+func floatExpression(fieldName string, between config.FromToFloat) node {
+	if between.From > 0 || between.To > 0 {
+		return &floatNode{field: fieldName, between: between}
+	}
+	return nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// topLevelNodes : the top level AND is split to keep the name of every filter.
+func topLevelNodes(n node) []node {
+	if and, ok := n.(*andNode); ok {
+		return and.children
+	}
+	return []node{n}
+}
+
+// nodeName : name of the filter compiled from the node,
+// the names of all the fields used by the node e.g. "BIO", "BIO+LOCATION"
+func nodeName(n node) string {
+	names := []string{}
+	seen := map[string]bool{}
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case *andNode:
+			for _, c := range n.children {
+				walk(c)
+			}
+		case *orNode:
+			for _, c := range n.children {
+				walk(c)
+			}
+		case *notNode:
+			walk(n.child)
+		case *textNode, *entityNode, *numberNode, *floatNode, *dateNode, *boolNode, *languageNode, *countryNode, *nearNode, *dictionaryNode:
+			name := fields[nodeField(n)].name
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	walk(n)
+	return strings.Join(names, "+")
+}
+
+func nodeField(n node) string {
+	switch n := n.(type) {
+	case *textNode:
+		return n.field
+	case *entityNode:
+		return n.field
+	case *numberNode:
+		return n.field
+	case *floatNode:
+		return n.field
+	case *dateNode:
+		return n.field
+	case *boolNode:
+		return n.field
+	case *languageNode:
+		return n.field
+	case *countryNode:
+		return n.field
+	case *dictionaryNode:
+		return n.field
+	case *nearNode:
+		return n.field
+	}
+	return ""
+}
+
+// nodePresent : check if all the fields used by the node are available for the user,
+// nil if the node uses only fields that are always available
+func nodePresent(n node) func(*profile) bool {
+	present := []func(*profile) bool{}
+	seen := map[string]bool{}
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case *andNode:
+			for _, c := range n.children {
+				walk(c)
+			}
+		case *orNode:
+			for _, c := range n.children {
+				walk(c)
+			}
+		case *notNode:
+			walk(n.child)
+		default:
+			name := nodeField(n)
+			if f := fields[name]; f.present != nil && !seen[name] {
+				seen[name] = true
+				present = append(present, f.present)
+			}
+		}
+	}
+	walk(n)
+	if len(present) == 0 {
+		return nil
+	}
+	return func(u *profile) bool {
+		for _, p := range present {
+			if !p(u) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// compileFilter : compile the top level node to filter,
+// MISSING_STATUS decides the whole filter (after NOT) when a field is not available for the user
+func (fd *Finder) compileFilter(n node) profileFilter {
+	compiled := fd.compile(n)
+	if present := nodePresent(n); present != nil {
+		return missingFilter(nodeName(n), present, fd.missingStatus, compiled)
+	}
+	return compiled
+}
+
+// compile : compile expression node to filter
+func (fd *Finder) compile(n node) profileFilter {
+	f, ok := fields[nodeField(n)]
+	compiled := fd.compileNode(n)
+	if ok && f.detail != nil {
+		compiled = detailFilter(f.detail, compiled)
+	}
+	return compiled
+}
+
+func (fd *Finder) compileNode(n node) profileFilter {
+	switch n := n.(type) {
+	case *andNode:
+		children := make([]profileFilter, 0, len(n.children))
+		for _, c := range n.children {
+			children = append(children, fd.compile(c))
+		}
+		return func(u *profile) FilterResult {
+			results := make([]FilterResult, 0, len(children))
+			for _, f := range children {
+				res := f(u)
+				if !res.Passed {
+					return res
+				}
+				results = append(results, res)
+			}
+			return combineResults(results, "AND", true)
+		}
+	case *orNode:
+		children := make([]profileFilter, 0, len(n.children))
+		for _, c := range n.children {
+			children = append(children, fd.compile(c))
+		}
+		return func(u *profile) FilterResult {
+			results := make([]FilterResult, 0, len(children))
+			for _, f := range children {
+				res := f(u)
+				if res.Passed {
+					return res
+				}
+				results = append(results, res)
+			}
+			return combineResults(results, "OR", false)
+		}
+	case *notNode:
+		child := fd.compile(n.child)
+		return func(u *profile) FilterResult {
+			res := child(u)
+			res.Passed = !res.Passed
+			res.Decider = "NOT " + res.Decider
+			res.Weight = 0
+			return res
+		}
+	case *textNode:
+		f := fields[n.field]
+		return textFilter(f.name, f.text, n.keyword)
+	case *entityNode:
+		f := fields[n.field]
+		return entityFilter(f.name, f.entities, n.keyword)
+	case *numberNode:
+		f := fields[n.field]
+		return numberFilter(f.name, f.number, n.between)
+	case *floatNode:
+		f := fields[n.field]
+		return floatFilter(f.name, f.float, n.between)
+	case *dateNode:
+		f := fields[n.field]
+		return dateFilter(f.name, f.date, n.between)
+	case *boolNode:
+		f := fields[n.field]
+		return boolFilter(f.name, f.flag, n.value)
+	case *languageNode:
+		f := fields[n.field]
+		return languageFilter(f.name, f.language, n.code, fd.languageConfidence)
+	case *dictionaryNode:
+		f := fields[n.field]
+		return dictionaryFilter(f.name, f.text, n.dict)
+	case *countryNode:
+		f := fields[n.field]
+		return countryFilter(f.name, f.place, n.code)
+	case *nearNode:
+		f := fields[n.field]
+		return nearFilter(f.name, f.place, n.center, n.km)
+	}
+	panic(fmt.Sprintf("finder: unknown expression node %T", n))
+}
+
+// languageExpression : any of the languages, nil if empty
+func languageExpression(codes []string) (node, error) {
+	or := &orNode{}
+	for _, c := range codes {
+		n, err := newLanguageNode("lang", c)
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			or.children = append(or.children, n)
+		}
+	}
+	switch len(or.children) {
+	case 0:
+		return nil, nil
+	case 1:
+		return or.children[0], nil
+	}
+	return or, nil
+}
+
+// newLanguageNode : validate the language code, nil if blank
+func newLanguageNode(fieldName, code string) (node, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return nil, nil
+	}
+	if !isSupportedLanguage(code) {
+		return nil, fmt.Errorf("%v: unsupported language %q, expected one of %v", fieldName, code, strings.Join(SupportedLanguages, ", "))
+	}
+	return &languageNode{field: fieldName, code: code}, nil
+}
+
+// countryExpression : any of the countries, nil if empty
+func countryExpression(codes []string) (node, error) {
+	or := &orNode{}
+	for _, c := range codes {
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+		code, err := parseCountry(c)
+		if err != nil {
+			return nil, fmt.Errorf("LOCATION_COUNTRIES: %v", err)
+		}
+		or.children = append(or.children, &countryNode{field: "country", code: code})
+	}
+	switch len(or.children) {
+	case 0:
+		return nil, nil
+	case 1:
+		return or.children[0], nil
+	}
+	return or, nil
+}
+
+// newNearNode : resolve the center city or "LAT,LON"
+func newNearNode(fieldName, center string, km float64) (node, error) {
+	if km <= 0 {
+		return nil, fmt.Errorf("the distance should be more than 0 km, found %v", km)
+	}
+	p, err := parsePlace(center)
+	if err != nil {
+		return nil, err
+	}
+	return &nearNode{field: fieldName, center: p, km: km}, nil
+}
+
+// flagExpression : flag criteria from the tri-state, nil if ignored
+func flagExpression(field string, state config.TriState) node {
+	switch state.Or(config.TriStateIgnore) {
+	case config.TriStateRequire:
+		return &boolNode{field: field, value: true}
+	case config.TriStateExclude:
+		return &boolNode{field: field, value: false}
+	}
+	return nil
+}
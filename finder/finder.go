@@ -1,261 +1,384 @@
 package finder
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
 	"twfinder/config"
 	"twfinder/helper"
+	"twfinder/logger"
 
 	"github.com/tarekbadrshalaan/anaconda"
 )
 
-var (
-	// internal finder object
-	intFinder       finder
-	buildFinderOnce sync.Once
-)
+// Filter : check the user profile against one criteria, the registered custom filters implement it.
+type Filter interface {
+	Match(user *anaconda.User) FilterResult
+}
 
-type filter func(*anaconda.User) (string, bool)
+// FilterFunc : adapter to use an ordinary function as Filter
+type FilterFunc func(*anaconda.User) FilterResult
 
-type finder struct {
-	searchHandleContext   []string
-	searchNameContext     []string
-	searchBioContext      []string
-	searchLocationContext []string
-	followersCountBetween config.FromToNumber
-	followingCountBetween config.FromToNumber
-	likesCountBetween     config.FromToNumber
-	tweetsCountBetween    config.FromToNumber
-	listsCountBetween     config.FromToNumber
-	joinedBetween         config.FromToDate
-	verified              bool
+// Match : call f(user)
+func (f FilterFunc) Match(user *anaconda.User) FilterResult {
+	return f(user)
+}
 
-	filters []filter
+// profileFilter : the compiled criteria, checked against the user being matched
+type profileFilter func(u *profile) FilterResult
+
+// Finder : check users against the search criteria
+type Finder struct {
+	expression node
+	// gates : filters that every user must pass, even in scoring mode
+	gates   []profileFilter
+	filters []profileFilter
+	// weights : the weight of every filter in scoring mode
+	weights []float64
+	// names : the names of the gates and the filters, in the match report order
+	names   []string
+	scoring config.Scoring
+	// missingStatus : result of the last activity filters for users without visible status
+	missingStatus bool
+	// languageConfidence : the minimum confidence of the detected language
+	languageConfidence float64
 }
 
-// CheckUserCriteria : check if the input user apply for configuration criteria
-func CheckUserCriteria(user *anaconda.User) bool {
-	for _, v := range intFinder.filters {
-		if _, fmatch := v(user); !fmatch {
-			return false
-		}
+// NewFinder : build new finder from the search criteria
+// the legacy criteria fields and the QUERY expression are combined with AND
+func NewFinder(sc config.SearchCriteria) (*Finder, error) {
+	if err := sc.Validate(); err != nil {
+		return nil, err
+	}
+	if sc.LanguageConfidence < 0 || sc.LanguageConfidence > 1 {
+		return nil, fmt.Errorf("LANGUAGE_CONFIDENCE %v should be between 0 and 1", sc.LanguageConfidence)
+	}
+	if sc.MaxBotScore < 0 || sc.MaxBotScore > 1 {
+		return nil, fmt.Errorf("MAX_BOT_SCORE %v should be between 0 and 1", sc.MaxBotScore)
+	}
+	if sc.LanguageConfidence == 0 {
+		sc.LanguageConfidence = defaultLanguageConfidence
 	}
-	return true
+	expr, err := buildExpression(sc)
+	if err != nil {
+		return nil, err
+	}
+	f := &Finder{
+		expression:         expr,
+		scoring:            sc.Scoring,
+		missingStatus:      strings.EqualFold(sc.MissingStatus, config.MissingStatusInclude),
+		languageConfidence: sc.LanguageConfidence,
+	}
+	logger.Infof("[Search Criteria] %v", expr)
+
+	// protected is a gate, protected accounts are excluded by default
+	// and can not be scored in, their followers/following are not visible.
+	if n := flagExpression("protected", sc.Protected.Or(config.TriStateExclude)); n != nil {
+		f.gates = append(f.gates, f.compileFilter(n))
+		f.names = append(f.names, nodeName(n))
+	}
+	for _, n := range topLevelNodes(expr) {
+		f.filters = append(f.filters, f.compileFilter(n))
+		f.weights = append(f.weights, filterWeight(f.scoring.Weights, nodeName(n)))
+		f.names = append(f.names, nodeName(n))
+	}
+	for _, c := range sc.CustomFilters {
+		custom, name, err := customFilter(c)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("[Search Criteria] custom filter %v %s", name, c.Params)
+		f.filters = append(f.filters, custom)
+		f.weights = append(f.weights, filterWeight(f.scoring.Weights, name))
+		f.names = append(f.names, name)
+	}
+	return f, nil
 }
 
-// BuildSearchCriteria : build interanl search criteria
-func BuildSearchCriteria() {
-	c := config.Configuration()
-	buildFinderOnce.Do(func() {
-		// all accounts in the system should not be protected
-		intFinder.filters = append(intFinder.filters, protectedFilter)
+// FilterNames : names of the criteria in the same order of the match report filters
+func (f *Finder) FilterNames() []string {
+	return append([]string{}, f.names...)
+}
 
-		intFinder.searchHandleContext = c.SearchCriteria.SearchHandleContext
-		if len(intFinder.searchHandleContext) > 1 {
-			intFinder.filters = append(intFinder.filters, handleFilter)
-		}
-		intFinder.searchNameContext = c.SearchCriteria.SearchNameContext
-		if len(intFinder.searchNameContext) > 1 {
-			intFinder.filters = append(intFinder.filters, nameFilter)
-		}
-		intFinder.searchBioContext = c.SearchCriteria.SearchBioContext
-		if len(intFinder.searchBioContext) > 1 {
-			intFinder.filters = append(intFinder.filters, bioFilter)
-		}
-		intFinder.searchLocationContext = c.SearchCriteria.SearchLocationContext
-		if len(intFinder.searchLocationContext) > 1 {
-			intFinder.filters = append(intFinder.filters, locationFilter)
-		}
-		intFinder.followersCountBetween = c.SearchCriteria.FollowersCountBetween
-		if intFinder.followersCountBetween.From > 0 || intFinder.followersCountBetween.To > 0 {
-			intFinder.filters = append(intFinder.filters, followersFilter)
-		}
-		intFinder.followingCountBetween = c.SearchCriteria.FollowingCountBetween
-		if intFinder.followingCountBetween.From > 0 || intFinder.followingCountBetween.To > 0 {
-			intFinder.filters = append(intFinder.filters, followingFilter)
-		}
-		intFinder.likesCountBetween = c.SearchCriteria.LikesCountBetween
-		if intFinder.likesCountBetween.From > 0 || intFinder.likesCountBetween.To > 0 {
-			intFinder.filters = append(intFinder.filters, likesFilter)
-		}
-		intFinder.tweetsCountBetween = c.SearchCriteria.TweetsCountBetween
-		if intFinder.tweetsCountBetween.From > 0 || intFinder.tweetsCountBetween.To > 0 {
-			intFinder.filters = append(intFinder.filters, tweetsFilter)
-		}
-		intFinder.listsCountBetween = c.SearchCriteria.ListsCountBetween
-		if intFinder.listsCountBetween.From > 0 || intFinder.listsCountBetween.To > 0 {
-			intFinder.filters = append(intFinder.filters, listsFilter)
-		}
-		intFinder.joinedBetween = c.SearchCriteria.JoinedBetween
-		if !intFinder.joinedBetween.From.IsZero() || !intFinder.joinedBetween.To.IsZero() {
-			intFinder.filters = append(intFinder.filters, joinedFilter)
-		}
-		intFinder.verified = c.SearchCriteria.Verified
-		if intFinder.verified {
-			intFinder.filters = append(intFinder.filters, verifiedFilter)
-		}
-	})
+// Match : check if the input user apply for the search criteria
+// every filter is evaluated to explain the result in the report,
+// in scoring mode the user match if the total score reach the threshold.
+// the language, place and bot score are computed only if a criteria uses them, see MatchReport.Describe.
+func (f *Finder) Match(user *anaconda.User) MatchReport {
+	return f.match(newProfile(user, nil))
 }
 
-func handleFilter(u *anaconda.User) (string, bool) {
-	match := false
-	for _, keyword := range intFinder.searchHandleContext {
-		if strings.HasPrefix(keyword, "-") && strings.Contains(strings.ToLower(u.ScreenName), strings.ToLower(keyword[1:])) {
-			match = false
-			break
-		}
-		if strings.Contains(strings.ToLower(u.ScreenName), strings.ToLower(keyword)) {
-			match = true
-		}
-	}
-	return "Handle", match
+// MatchProfile : Match the looked up profile, the t.co links of the bio are expanded with its BioURLs
+func (f *Finder) MatchProfile(p *Profile) MatchReport {
+	return f.match(newProfile(&p.User, p.BioURLs))
 }
 
-func nameFilter(u *anaconda.User) (string, bool) {
-	match := false
-	for _, keyword := range intFinder.searchNameContext {
-		if strings.HasPrefix(keyword, "-") && strings.Contains(strings.ToLower(u.Name), strings.ToLower(keyword[1:])) {
-			match = false
-			break
-		}
-		if strings.Contains(strings.ToLower(u.Name), strings.ToLower(keyword)) {
-			match = true
-		}
+func (f *Finder) match(u *profile) MatchReport {
+	report := MatchReport{Matched: true, Filters: make([]FilterResult, 0, len(f.gates)+len(f.filters)), profile: u}
+	gatesPassed := true
+	for _, v := range f.gates {
+		res := v(u)
+		if !res.Passed {
+			gatesPassed = false
+		}
+		report.Filters = append(report.Filters, res)
+	}
+	allPassed := true
+	for i, v := range f.filters {
+		res := v(u)
+		if res.Passed {
+			res.Score = f.weights[i] * res.keywordWeight()
+			report.Score += res.Score
+		} else {
+			allPassed = false
+		}
+		report.Filters = append(report.Filters, res)
 	}
-	return "Name", match
-}
 
-func bioFilter(u *anaconda.User) (string, bool) {
-	match := false
-	for _, keyword := range intFinder.searchBioContext {
-		if strings.HasPrefix(keyword, "-") && strings.Contains(strings.ToLower(u.Description), strings.ToLower(keyword[1:])) {
-			match = false
-			break
-		}
-		if strings.Contains(strings.ToLower(u.Description), strings.ToLower(keyword)) {
-			match = true
-		}
+	report.Matched = gatesPassed && allPassed
+	if f.scoring.Enabled {
+		report.Matched = gatesPassed && report.Score >= f.scoring.Threshold
 	}
-	return "BIO", match
+	// the language, place and bot score used by the criteria, the others are added by Describe
+	u.computed(&report)
+	return report
 }
 
-func locationFilter(u *anaconda.User) (string, bool) {
-	match := false
-	for _, keyword := range intFinder.searchLocationContext {
-		if strings.HasPrefix(keyword, "-") && strings.Contains(strings.ToLower(u.Location), strings.ToLower(keyword[1:])) {
-			match = false
-			break
-		}
-		if strings.Contains(strings.ToLower(u.Location), strings.ToLower(keyword)) {
-			match = true
+// RecursiveMatch : check if the followers/following of the user should be investigated
+// when only successful users are used, in scoring mode RECURSIVE_THRESHOLD is used.
+func (f *Finder) RecursiveMatch(report MatchReport) bool {
+	if !f.scoring.Enabled || f.scoring.RecursiveThreshold <= 0 {
+		return report.Matched
+	}
+	for _, g := range report.Filters[:len(f.gates)] {
+		if !g.Passed {
+			return false
 		}
 	}
-	return "LOCATION", match
+	return report.Score >= f.scoring.RecursiveThreshold
 }
 
-func followersFilter(u *anaconda.User) (string, bool) {
-	match := true
-	if intFinder.followersCountBetween.From > 0 {
-		if int64(u.FollowersCount) <= intFinder.followersCountBetween.From {
-			match = false
+// filterWeight : weight of the filter name, default 1
+func filterWeight(weights map[string]float64, name string) float64 {
+	for k, v := range weights {
+		if strings.EqualFold(k, name) {
+			return v
 		}
 	}
-	if intFinder.followersCountBetween.To > 0 {
-		if int64(u.FollowersCount) >= intFinder.followersCountBetween.To {
-			match = false
-		}
+	return 1
+}
+
+// textFilter : match if the keyword match the user text field
+func textFilter(name string, value func(*profile) string, keyword *matcher) profileFilter {
+	return func(u *profile) FilterResult {
+		v := value(u)
+		passed, detail := keyword.explain(v)
+		if detail != "" {
+			v = fmt.Sprintf("%v (%v)", v, detail)
+		}
+		return FilterResult{Filter: name, Passed: passed, Decider: keyword.String(), Value: v, Weight: keyword.weight}
 	}
-	return "FOLLOWERS", match
 }
 
-func followingFilter(u *anaconda.User) (string, bool) {
-	match := true
-	if intFinder.followingCountBetween.From > 0 {
-		if int64(u.FriendsCount) <= intFinder.followingCountBetween.From {
-			match = false
-		}
+// dictionaryFilter : match if any keyword of the keyword file match the user text
+func dictionaryFilter(name string, value func(*profile) string, dict *dictionary) profileFilter {
+	return func(u *profile) FilterResult {
+		v := value(u)
+		res := FilterResult{Filter: name, Decider: dict.String(), Value: v}
+		if m, ok := dict.match(v); ok {
+			res.Passed = true
+			res.Decider = m.String()
+			res.Weight = m.weight
+		}
+		return res
 	}
-	if intFinder.followingCountBetween.To > 0 {
-		if int64(u.FriendsCount) >= intFinder.followingCountBetween.To {
-			match = false
-		}
+}
+
+// entityFilter : match if the keyword match one of the profile entities
+func entityFilter(name string, values func(*profile) []string, keyword *entityKeyword) profileFilter {
+	return func(u *profile) FilterResult {
+		v := values(u)
+		res := FilterResult{Filter: name, Decider: keyword.String(), Value: strings.Join(v, " ")}
+		if found, ok := keyword.match(v); ok {
+			res.Passed = true
+			res.Value = found
+		}
+		return res
 	}
-	return "FOLLOWING", match
 }
 
-func likesFilter(u *anaconda.User) (string, bool) {
-	match := true
-	if intFinder.likesCountBetween.From > 0 {
-		if int64(u.FavouritesCount) <= intFinder.likesCountBetween.From {
-			match = false
-		}
+// numberFilter : match if the user number field is between (From, To)
+// zero From/To is ignored
+func numberFilter(name string, value func(*profile) int64, between config.FromToNumber) profileFilter {
+	return func(u *profile) FilterResult {
+		v := value(u)
+		res := FilterResult{Filter: name, Passed: true, Value: strconv.FormatInt(v, 10)}
+		res.Decider = formatRange(between.From, between.To, 0, func(v int64) string {
+			return strconv.FormatInt(v, 10)
+		})
+		if between.From > 0 {
+			if v <= between.From {
+				res.Passed = false
+				res.Decider = fmt.Sprintf("FROM %v", between.From)
+			}
+		}
+		if between.To > 0 {
+			if v >= between.To {
+				res.Passed = false
+				res.Decider = fmt.Sprintf("TO %v", between.To)
+			}
+		}
+		return res
 	}
-	if intFinder.likesCountBetween.To > 0 {
-		if int64(u.FavouritesCount) >= intFinder.likesCountBetween.To {
-			match = false
-		}
+}
+
+// floatFilter : match if the user metric is between (From, To)
+// zero From/To is ignored
+func floatFilter(name string, value func(*profile) float64, between config.FromToFloat) profileFilter {
+	return func(u *profile) FilterResult {
+		v := value(u)
+		res := FilterResult{Filter: name, Passed: true, Value: strconv.FormatFloat(v, 'f', 2, 64)}
+		res.Decider = formatRange(between.From, between.To, 0, formatFloat)
+		if between.From > 0 {
+			if v <= between.From {
+				res.Passed = false
+				res.Decider = fmt.Sprintf("FROM %v", formatFloat(between.From))
+			}
+		}
+		if between.To > 0 {
+			if v >= between.To {
+				res.Passed = false
+				res.Decider = fmt.Sprintf("TO %v", formatFloat(between.To))
+			}
+		}
+		return res
 	}
-	return "LIKES", match
 }
 
-func tweetsFilter(u *anaconda.User) (string, bool) {
-	match := true
-	if intFinder.tweetsCountBetween.From > 0 {
-		if int64(u.StatusesCount) <= intFinder.tweetsCountBetween.From {
-			match = false
-		}
+// dateFilter : match if the user date field is between (From, To)
+// zero From/To is ignored
+func dateFilter(name string, value func(*profile) time.Time, between config.FromToDate) profileFilter {
+	return func(u *profile) FilterResult {
+		v := value(u)
+		unx := v.Unix()
+		res := FilterResult{Filter: name, Passed: true, Value: v.Format(dateLayout)}
+		res.Decider = formatRange(between.From, between.To, time.Time{}, func(v time.Time) string {
+			return v.Format(dateLayout)
+		})
+		if !between.From.IsZero() {
+			if unx <= between.From.Unix() {
+				res.Passed = false
+				res.Decider = fmt.Sprintf("FROM %v", between.From.Format(dateLayout))
+			}
+		}
+		if !between.To.IsZero() {
+			if unx >= between.To.Unix() {
+				res.Passed = false
+				res.Decider = fmt.Sprintf("TO %v", between.To.Format(dateLayout))
+			}
+		}
+		return res
 	}
-	if intFinder.tweetsCountBetween.To > 0 {
-		if int64(u.StatusesCount) >= intFinder.tweetsCountBetween.To {
-			match = false
-		}
+}
+
+// boolFilter : match if the user flag equal the expected value
+func boolFilter(name string, value func(*profile) bool, expected bool) profileFilter {
+	return func(u *profile) FilterResult {
+		v := value(u)
+		return FilterResult{Filter: name, Passed: v == expected, Decider: strconv.FormatBool(expected), Value: strconv.FormatBool(v)}
 	}
-	return "TWEETS", match
 }
 
-func listsFilter(u *anaconda.User) (string, bool) {
-	match := true
-	if intFinder.tweetsCountBetween.From > 0 {
-		if int64(u.StatusesCount) <= intFinder.tweetsCountBetween.From {
-			match = false
-		}
+// languageFilter : match if the detected language is the code with enough confidence
+func languageFilter(name string, value func(*profile) Language, code string, confidence float64) profileFilter {
+	decider := strconv.Quote(code)
+	if confidence > 0 {
+		decider = fmt.Sprintf("%v >= %v", decider, confidence)
 	}
-	if intFinder.tweetsCountBetween.To > 0 {
-		if int64(u.StatusesCount) >= intFinder.tweetsCountBetween.To {
-			match = false
+	return func(u *profile) FilterResult {
+		l := value(u)
+		res := FilterResult{Filter: name, Passed: l.Code == code && l.Confidence >= confidence, Decider: decider, Value: "<none>"}
+		if l.Code != "" {
+			res.Value = fmt.Sprintf("%v (%v)", l.Code, l.Confidence)
 		}
+		return res
 	}
-	return "LISTS", match
 }
 
-func joinedFilter(u *anaconda.User) (string, bool) {
-	match := true
-	joinedUnx := helper.StringtoDate(u.CreatedAt, "").Unix()
-	if !intFinder.joinedBetween.From.IsZero() {
-		if joinedUnx <= intFinder.joinedBetween.From.Unix() {
-			match = false
-		}
+// countryFilter : match if the user location is resolved to the country
+func countryFilter(name string, value func(*profile) Place, code string) profileFilter {
+	return func(u *profile) FilterResult {
+		p := value(u)
+		return FilterResult{Filter: name, Passed: p.Country == code, Decider: strconv.Quote(code), Value: p.String()}
 	}
-	if !intFinder.joinedBetween.To.IsZero() {
-		if joinedUnx >= intFinder.joinedBetween.To.Unix() {
-			match = false
-		}
+}
+
+// nearFilter : match if the user location is a city within km of the center
+func nearFilter(name string, value func(*profile) Place, center Place, km float64) profileFilter {
+	decider := fmt.Sprintf("within %vkm of %v", formatFloat(km), center)
+	return func(u *profile) FilterResult {
+		p := value(u)
+		res := FilterResult{Filter: name, Decider: decider, Value: p.String()}
+		if p.hasCoordinates() {
+			d := distanceKm(center, p)
+			res.Passed = d <= km
+			res.Value = fmt.Sprintf("%v (%.0fkm)", p, d)
+		}
+		return res
 	}
-	return "JOINED", match
 }
 
-func verifiedFilter(u *anaconda.User) (string, bool) {
-	match := true
-	if intFinder.verified && !u.Verified {
-		match = false
+// detailFilter : append the field explanation to the result value
+func detailFilter(detail func(*profile) string, f profileFilter) profileFilter {
+	return func(u *profile) FilterResult {
+		res := f(u)
+		res.Value = fmt.Sprintf("%v (%v)", res.Value, detail(u))
+		return res
 	}
-	return "VERIFIED", match
 }
 
-func protectedFilter(u *anaconda.User) (string, bool) {
-	match := true
-	if u.Protected {
-		match = false
+// missingFilter : decide the result when the field is not available for the user
+// e.g. the user has no visible status, otherwise use the field filter
+func missingFilter(name string, present func(*profile) bool, missing bool, f profileFilter) profileFilter {
+	return func(u *profile) FilterResult {
+		if !present(u) {
+			return FilterResult{Filter: name, Passed: missing, Decider: "MISSING_STATUS", Value: "<none>"}
+		}
+		return f(u)
 	}
-	return "PROTECTED", match
+}
+
+// user fields accessors used by the filters.
+func userHandle(u *profile) string   { return u.ScreenName }
+func userName(u *profile) string     { return u.Name }
+func userBio(u *profile) string      { return u.Description }
+func userLocation(u *profile) string { return u.Location }
+func userFollowers(u *profile) int64 { return int64(u.FollowersCount) }
+func userFollowing(u *profile) int64 { return int64(u.FriendsCount) }
+func userLikes(u *profile) int64     { return int64(u.FavouritesCount) }
+func userTweets(u *profile) int64    { return u.StatusesCount }
+func userLists(u *profile) int64     { return u.ListedCount }
+func userVerified(u *profile) bool   { return u.Verified }
+func userProtected(u *profile) bool  { return u.Protected }
+func userDefaultProfile(u *profile) bool {
+	return u.DefaultProfile
+}
+func userDefaultProfileImage(u *profile) bool {
+	return u.DefaultProfileImage
+}
+func userGeoEnabled(u *profile) bool {
+	return u.GeoEnabled
+}
+func userJoined(u *profile) time.Time {
+	return helper.StringtoDate(u.CreatedAt, "")
+}
+func userHasStatus(u *profile) bool {
+	return u.Status != nil && u.Status.CreatedAt != ""
+}
+func userLastTweet(u *profile) time.Time {
+	return helper.StringtoDate(u.Status.CreatedAt, "")
+}
+func userInactiveDays(u *profile) int64 {
+	return daysSince(userLastTweet(u))
 }
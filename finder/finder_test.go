@@ -0,0 +1,159 @@
+package finder
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+func TestLastActivityMatch(t *testing.T) {
+	recordErrors(t)
+	tweetedAt := func(days int) *anaconda.Tweet {
+		return &anaconda.Tweet{CreatedAt: time.Now().AddDate(0, 0, -days).Format(time.RubyDate)}
+	}
+	users := map[string]anaconda.User{
+		"active": {ScreenName: "active", Status: tweetedAt(2)},
+		"stale":  {ScreenName: "stale", Status: tweetedAt(400)},
+		"silent": {ScreenName: "silent"},
+	}
+	monthAgo := time.Now().AddDate(0, 0, -30)
+	since := monthAgo.Format(dateLayout)
+	tests := []struct {
+		name     string
+		criteria config.SearchCriteria
+		matched  []string
+	}{
+		{"LAST_TWEETED_BETWEEN", config.SearchCriteria{LastTweetedBetween: config.FromToDate{From: monthAgo}}, []string{"active"}},
+		{"LAST_TWEETED_BETWEEN INCLUDE", config.SearchCriteria{LastTweetedBetween: config.FromToDate{From: monthAgo}, MissingStatus: "include"}, []string{"active", "silent"}},
+		{"EXCLUDE_INACTIVE_DAYS", config.SearchCriteria{ExcludeInactiveDays: 30}, []string{"active"}},
+		{"EXCLUDE_INACTIVE_DAYS INCLUDE", config.SearchCriteria{ExcludeInactiveDays: 30, MissingStatus: "INCLUDE"}, []string{"active", "silent"}},
+		{"NOT last_tweet", config.SearchCriteria{Query: "NOT last_tweet:" + since + ".."}, []string{"stale"}},
+		{"NOT last_tweet INCLUDE", config.SearchCriteria{Query: "NOT last_tweet:" + since + "..", MissingStatus: "INCLUDE"}, []string{"stale", "silent"}},
+		{"NOT inactive_days", config.SearchCriteria{Query: "-inactive_days:..30"}, []string{"stale"}},
+		{"NOT NOT inactive_days", config.SearchCriteria{Query: "NOT NOT inactive_days:..30"}, []string{"active"}},
+		{"NOT inactive_days INCLUDE", config.SearchCriteria{Query: "-inactive_days:..30", MissingStatus: "INCLUDE"}, []string{"stale", "silent"}},
+		{"NOT (last_tweet OR inactive_days)", config.SearchCriteria{Query: "NOT (last_tweet:" + since + ".. OR inactive_days:..30)"}, []string{"stale"}},
+	}
+	for _, tt := range tests {
+		f, err := NewFinder(tt.criteria)
+		if err != nil {
+			t.Errorf("%v: %v", tt.name, err)
+			continue
+		}
+		expected := map[string]bool{}
+		for _, name := range tt.matched {
+			expected[name] = true
+		}
+		for name, u := range users {
+			u := u
+			report := f.Match(&u)
+			if report.Matched != expected[name] {
+				t.Errorf("%v: %v matched %v, expected %v: %v", tt.name, name, report.Matched, expected[name], report)
+			}
+			if last := report.Filters[len(report.Filters)-1]; name == "silent" && last.Decider != "MISSING_STATUS" {
+				t.Errorf("%v: %v decided by %v, expected MISSING_STATUS", tt.name, name, last)
+			}
+		}
+	}
+
+	if _, err := NewFinder(config.SearchCriteria{ExcludeInactiveDays: 30, MissingStatus: "maybe"}); err == nil {
+		t.Error("MISSING_STATUS maybe accepted")
+	}
+}
+
+func TestProtectedDefault(t *testing.T) {
+	recordErrors(t)
+	users := map[string]anaconda.User{
+		"public":    {ScreenName: "public"},
+		"protected": {ScreenName: "protected", Protected: true},
+	}
+	tests := []struct {
+		state   config.TriState
+		matched []string
+	}{
+		{"", []string{"public"}},
+		{config.TriStateExclude, []string{"public"}},
+		{"ignore", []string{"public", "protected"}},
+		{"Require", []string{"protected"}},
+	}
+	for _, tt := range tests {
+		f, err := NewFinder(config.SearchCriteria{Protected: tt.state})
+		if err != nil {
+			t.Errorf("PROTECTED %q: %v", tt.state, err)
+			continue
+		}
+		expected := map[string]bool{}
+		for _, name := range tt.matched {
+			expected[name] = true
+		}
+		for name, u := range users {
+			u := u
+			if report := f.Match(&u); report.Matched != expected[name] {
+				t.Errorf("PROTECTED %q: %v matched %v, expected %v: %v", tt.state, name, report.Matched, expected[name], report)
+			}
+		}
+	}
+
+	if _, err := NewFinder(config.SearchCriteria{Protected: "maybe"}); err == nil || !strings.Contains(err.Error(), "PROTECTED") {
+		t.Errorf("PROTECTED maybe: error %v", err)
+	}
+}
+
+func TestConcurrentFinders(t *testing.T) {
+	recordErrors(t)
+	golang, err := NewFinder(config.SearchCriteria{SearchBioContext: []string{"golang"}, FollowersCountBetween: config.FromToNumber{From: 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rust, err := NewFinder(config.SearchCriteria{SearchBioContext: []string{"rust"}, Protected: "ignore"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := []anaconda.User{
+		{Description: "golang dev", FollowersCount: 500},
+		{Description: "golang dev", FollowersCount: 50},
+		{Description: "rust dev", Protected: true},
+		{Description: "rust and golang", FollowersCount: 500},
+	}
+	expected := map[*Finder][]bool{
+		golang: {true, false, false, true},
+		rust:   {false, false, true, true},
+	}
+
+	errs := make(chan string, 1)
+	var wg sync.WaitGroup
+	for f, matched := range expected {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(f *Finder, matched []bool) {
+				defer wg.Done()
+				for n := 0; n < 50; n++ {
+					for i, u := range users {
+						u := u
+						if report := f.Match(&u); report.Matched != matched[i] {
+							select {
+							case errs <- fmt.Sprintf("%v %q matched %v, expected %v: %v", f.FilterNames(), u.Description, report.Matched, matched[i], report):
+							default:
+							}
+						}
+					}
+				}
+			}(f, matched)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		t.Error(e)
+		break
+	}
+	// every finder keeps its own criteria
+	if fmt.Sprint(golang.FilterNames()) != "[PROTECTED BIO FOLLOWERS]" || fmt.Sprint(rust.FilterNames()) != "[BIO]" {
+		t.Errorf("filter names %v %v", golang.FilterNames(), rust.FilterNames())
+	}
+}
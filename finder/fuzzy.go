@@ -0,0 +1,271 @@
+package finder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// fuzzy matching for names and handles e.g. "Mohamed", "Mohammed", "Muhammad" and "محمد",
+// the keyword is compared with every group of words in the text of the same size,
+// handles are split on digits, underscores and case changes e.g. "MohamedAli_99" is [mohamed ali]
+// and a single word keyword is compared with the parts of run together words e.g. "mohamedali",
+// directly and with a transliterated consonant skeleton (e.g. "mhmd") that ignores the vowels spelling.
+
+// defaultFuzzySimilarity : similarity threshold if the keyword has no "~threshold"
+const defaultFuzzySimilarity = 0.8
+
+// minSkeletonLen : shorter skeletons are too ambiguous to compare e.g. "Ali" and "Ola"
+const minSkeletonLen = 3
+
+// arabicLatin : transliteration of the arabic script letters,
+// vowels are dropped from the skeleton except at the start of the text.
+var arabicLatin = map[rune]string{
+	'ا': "a", 'أ': "a", 'إ': "a", 'آ': "a", 'ٱ': "a", 'ب': "b", 'ت': "t", 'ث': "th",
+	'ج': "j", 'ح': "h", 'خ': "kh", 'د': "d", 'ذ': "dh", 'ر': "r", 'ز': "z", 'س': "s",
+	'ش': "sh", 'ص': "s", 'ض': "d", 'ط': "t", 'ظ': "z", 'ع': "a", 'غ': "gh", 'ف': "f",
+	'ق': "k", 'ك': "k", 'ک': "k", 'ل': "l", 'م': "m", 'ن': "n", 'ه': "h", 'ة': "h",
+	'و': "w", 'ؤ': "w", 'ي': "y", 'ى': "y", 'ی': "y", 'ئ': "y", 'ء': "",
+	'پ': "p", 'چ': "ch", 'ژ': "zh", 'گ': "g",
+}
+
+// fuzzyKeyword : fuzzy threshold, edit distance if maxDistance > 0 otherwise similarity
+type fuzzyKeyword struct {
+	maxDistance   int
+	minSimilarity float64
+	// skeleton : transliterated consonant skeleton of the keyword
+	skeleton string
+	arabic   bool
+	words    int
+}
+
+// fuzzyHit : the closest words in the text
+type fuzzyHit struct {
+	text       string
+	distance   int
+	similarity float64
+}
+
+func (h fuzzyHit) String() string {
+	return fmt.Sprintf("%q similarity %v distance %v", h.text, round2(h.similarity), h.distance)
+}
+
+// splitFuzzyThreshold : split "keyword~threshold" e.g. "Mohamed~2", "Mohamed~0.75"
+func splitFuzzyThreshold(keyword string) (string, string) {
+	idx := strings.LastIndexByte(keyword, '~')
+	if idx <= 0 {
+		return keyword, ""
+	}
+	return keyword[:idx], keyword[idx+1:]
+}
+
+// setThreshold : integer edit distance (>= 1) or similarity (0 to 1), empty for the default
+func (k *fuzzyKeyword) setThreshold(threshold string) error {
+	k.maxDistance, k.minSimilarity = 0, defaultFuzzySimilarity
+	if threshold == "" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(threshold, 64)
+	switch {
+	case err != nil || v <= 0:
+		return fmt.Errorf("invalid fuzzy threshold %q, expected edit distance or similarity", threshold)
+	case v < 1:
+		k.minSimilarity = v
+	case v == float64(int(v)):
+		k.maxDistance = int(v)
+	default:
+		return fmt.Errorf("invalid fuzzy threshold %q, expected integer edit distance or similarity less than 1", threshold)
+	}
+	return nil
+}
+
+func (k *fuzzyKeyword) threshold() string {
+	if k.maxDistance > 0 {
+		return strconv.Itoa(k.maxDistance)
+	}
+	return formatFloat(k.minSimilarity)
+}
+
+func newFuzzyKeyword(folded string) *fuzzyKeyword {
+	k := &fuzzyKeyword{minSimilarity: defaultFuzzySimilarity}
+	k.skeleton = skeleton(folded)
+	k.arabic = hasArabic(folded)
+	k.words = len(fuzzyWords(folded))
+	return k
+}
+
+// closest : the closest group of words in the folded text to the folded keyword
+func (k *fuzzyKeyword) closest(keyword, text string) (fuzzyHit, bool) {
+	words := fuzzyWords(text)
+	best := fuzzyHit{distance: -1}
+	consider := func(candidate string) {
+		if h := k.compare(keyword, candidate); best.distance < 0 || h.similarity > best.similarity {
+			best = h
+		}
+	}
+	for i := 0; i+k.words <= len(words); i++ {
+		consider(strings.Join(words[i:i+k.words], " "))
+	}
+	if k.words == 1 {
+		for _, w := range words {
+			for _, part := range wordParts(w) {
+				consider(part)
+			}
+		}
+	}
+	// handles and names without spaces e.g. "mohamedali"
+	if len(words) != k.words {
+		consider(strings.Join(words, ""))
+	}
+	if best.distance < 0 {
+		return best, false
+	}
+	if k.maxDistance > 0 {
+		return best, best.distance <= k.maxDistance
+	}
+	return best, best.similarity >= k.minSimilarity
+}
+
+// compare : compare the keyword with the candidate words,
+// in the same script the similarity is the best of the direct and the average with the skeleton similarity,
+// and the distance is the direct edit distance, between scripts only the skeletons are compared.
+func (k *fuzzyKeyword) compare(keyword, candidate string) fuzzyHit {
+	a, b := strings.ReplaceAll(keyword, " ", ""), strings.ReplaceAll(candidate, " ", "")
+	if k.arabic != hasArabic(b) {
+		d, sim := editSimilarity(k.skeleton, skeleton(b))
+		return fuzzyHit{text: candidate, distance: d, similarity: sim}
+	}
+	d, sim := editSimilarity(a, b)
+	if len(k.skeleton) >= minSkeletonLen {
+		if _, skeletonSim := editSimilarity(k.skeleton, skeleton(b)); (sim+skeletonSim)/2 > sim {
+			sim = (sim + skeletonSim) / 2
+		}
+	}
+	return fuzzyHit{text: candidate, distance: d, similarity: sim}
+}
+
+// fuzzyWords : words of letters only, e.g. "mohamed_ali99" is [mohamed ali]
+func fuzzyWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r)
+	})
+}
+
+// splitHandle : separate the words of a handle before folding,
+// at underscores, digits and lower to upper case changes e.g. "MohamedAli_99" is "Mohamed Ali 99"
+func splitHandle(text string) string {
+	var b strings.Builder
+	var last rune
+	for _, r := range text {
+		if r == '_' {
+			r = ' '
+		}
+		if last != 0 && (unicode.IsDigit(r) && unicode.IsLetter(last) ||
+			unicode.IsLetter(r) && unicode.IsDigit(last) ||
+			unicode.IsUpper(r) && unicode.IsLower(last)) {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+// wordParts : the two parts of a run together word e.g. "mohamedali" is "mohamed" and "ali" among others,
+// both parts have at least minSkeletonLen letters so "alice" is not "ali" and "ce".
+func wordParts(word string) []string {
+	runes := []rune(word)
+	var parts []string
+	for i := minSkeletonLen; i+minSkeletonLen <= len(runes); i++ {
+		parts = append(parts, string(runes[:i]), string(runes[i:]))
+	}
+	return parts
+}
+
+func hasArabic(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Arabic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// skeleton : transliterated consonants, vowels dropped except at the start,
+// repeated letters collapsed and trailing h dropped, e.g. "Mohammed" and "محمد" are "mhmd".
+func skeleton(s string) string {
+	var latin strings.Builder
+	for _, r := range s {
+		if t, ok := arabicLatin[r]; ok {
+			latin.WriteString(t)
+		} else if unicode.IsLetter(r) {
+			latin.WriteRune(r)
+		}
+	}
+	text := latin.String()
+	for _, d := range [][2]string{{"ph", "f"}, {"ck", "k"}, {"q", "k"}} {
+		text = strings.ReplaceAll(text, d[0], d[1])
+	}
+	var b strings.Builder
+	var last rune
+	for i, r := range text {
+		vowel := strings.ContainsRune("aeiou", r)
+		if i > 0 && (vowel || r == 'y' || r == 'w') {
+			continue
+		}
+		if vowel {
+			r = 'a'
+		}
+		if r == last {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	res := b.String()
+	if len(res) > 2 && strings.HasSuffix(res, "h") {
+		res = res[:len(res)-1]
+	}
+	return res
+}
+
+// editSimilarity : levenshtein distance and the similarity 1 - distance / longest length
+func editSimilarity(a, b string) (int, float64) {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0, 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	d := prev[len(rb)]
+	return d, 1 - float64(d)/float64(longest)
+}
+
+func minInt(values ...int) int {
+	res := values[0]
+	for _, v := range values[1:] {
+		if v < res {
+			res = v
+		}
+	}
+	return res
+}
@@ -0,0 +1,93 @@
+package finder
+
+import (
+	"testing"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		// README examples
+		{"mohamed", "mhmd"},
+		{"mohammed", "mhmd"},
+		{"muhammad", "mhmd"},
+		{"محمد", "mhmd"},
+		{"ahmed", "ahmd"},
+		{"sarah", "sr"},
+	}
+	for _, tt := range tests {
+		if got := skeleton(tt.in); got != tt.expected {
+			t.Errorf("skeleton(%q) = %q, expected %q", tt.in, got, tt.expected)
+		}
+	}
+}
+
+func TestSplitHandle(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"mohamedali99", "mohamedali 99"},
+		{"MohamedAli_99", "Mohamed Ali 99"},
+		{"mohamed_ali", "mohamed ali"},
+		{"john2024smith", "john 2024 smith"},
+		{"NASA", "NASA"},
+		{"محمد_علي", "محمد علي"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := splitHandle(tt.in); got != tt.expected {
+			t.Errorf("splitHandle(%q) = %q, expected %q", tt.in, got, tt.expected)
+		}
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	recordErrors(t)
+	tests := []struct {
+		keyword string
+		handle  string
+		name    string
+		matched bool
+	}{
+		// README examples
+		{"fuzzy:Mohamed", "", "Mohammed Salah", true},
+		{"fuzzy:Mohamed", "", "Muhammad Ali", true},
+		{"fuzzy:Mohamed", "", "محمد صلاح", true},
+		{"fuzzy:محمد", "", "Mohamed Salah", true},
+		// handles split on digits, underscores and case changes, or run together
+		{"fuzzy:Mohamed", "mohamedali99", "", true},
+		{"fuzzy:Mohamed", "MuhammadAli", "", true},
+		{"fuzzy:Mohamed", "ali_mohammed_1", "", true},
+		{"fuzzy:Mohamed", "alimohamed", "", true},
+		{"fuzzy:Mohamed Ali", "mohamedali99", "", true},
+		// thresholds
+		{"fuzzy:John~1", "", "Jon Snow", true},
+		{"fuzzy:John~1", "", "Joan Snow", true},
+		{"fuzzy:John~1", "", "Jane Snow", false},
+		{"fuzzy:Mohamed~0.95", "", "Muhammad", false},
+		// short words are not split off run together words
+		{"fuzzy:Ali", "alice", "", false},
+		{"fuzzy:Mohamed", "ahmed_ali", "", false},
+		{"fuzzy:Mohamed", "", "Sarah Jones", false},
+	}
+	for _, tt := range tests {
+		criteria := config.SearchCriteria{SearchNameContext: []string{tt.keyword}}
+		if tt.handle != "" {
+			criteria = config.SearchCriteria{SearchHandleContext: []string{tt.keyword}}
+		}
+		f, err := NewFinder(criteria)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := anaconda.User{ScreenName: tt.handle, Name: tt.name}
+		if report := f.Match(&u); report.Matched != tt.matched {
+			t.Errorf("%q in handle %q name %q: matched %v, expected %v", tt.keyword, tt.handle, tt.name, report.Matched, tt.matched)
+		}
+	}
+}
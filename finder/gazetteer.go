@@ -0,0 +1,332 @@
+package finder
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// offline gazetteer, resolve the free text location to a city or a country
+
+const earthRadiusKm = 6371.0
+
+// Place : resolved location of the user
+type Place struct {
+	// City : empty if only the country is known
+	City string `json:"CITY,omitempty"`
+	// Region : state or province code e.g. "NH", if found in the location
+	Region string `json:"REGION,omitempty"`
+	// Country : ISO 3166-1 alpha-2 code e.g. "DE", empty if not resolved
+	Country string  `json:"COUNTRY"`
+	Lat     float64 `json:"LAT,omitempty"`
+	Lon     float64 `json:"LON,omitempty"`
+}
+
+// hasCoordinates : only cities have coordinates
+func (p Place) hasCoordinates() bool {
+	return p.City != ""
+}
+
+func (p Place) String() string {
+	parts := []string{}
+	for _, v := range []string{p.City, p.Region, p.Country} {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	if len(parts) == 0 {
+		return "<unknown>"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// distanceKm : great-circle distance between two places
+func distanceKm(a, b Place) float64 {
+	rad := math.Pi / 180
+	dLat := (b.Lat - a.Lat) * rad
+	dLon := (b.Lon - a.Lon) * rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(a.Lat*rad)*math.Cos(b.Lat*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// placeAlias : one name of a gazetteer entry
+type placeAlias struct {
+	text string
+	// exact : compare with the original location text instead of the normalized one
+	exact bool
+	// inWord : scripts without spaces between the words e.g. 東京在住
+	inWord bool
+}
+
+type gazetteerEntry struct {
+	place   Place
+	aliases []placeAlias
+}
+
+var (
+	gazetteerCountryList []gazetteerEntry
+	gazetteerRegionList  []gazetteerEntry
+	gazetteerCityList    []gazetteerEntry
+	// countryNames : country code to name
+	countryNames = map[string]string{}
+	// regionCountries : region code to country codes e.g. "CA": ["US"]
+	regionCountries = map[string][]string{}
+	// trailingCodeRegex : "City, XX" state, province or country code at the end of the location
+	trailingCodeRegex = regexp.MustCompile(`,\s*([A-Z]{2})\s*$`)
+)
+
+func init() {
+	for _, cols := range gazetteerLines(gazetteerCountries, 3) {
+		countryNames[cols[0]] = cols[1]
+		gazetteerCountryList = append(gazetteerCountryList, gazetteerEntry{
+			place:   Place{Country: cols[0]},
+			aliases: placeAliases(cols[1] + ";" + cols[2]),
+		})
+	}
+	for _, cols := range gazetteerLines(gazetteerRegions, 3) {
+		regionCountries[cols[1]] = append(regionCountries[cols[1]], cols[0])
+		gazetteerRegionList = append(gazetteerRegionList, gazetteerEntry{
+			place:   Place{Region: cols[1], Country: cols[0]},
+			aliases: placeAliases(cols[2]),
+		})
+	}
+	for _, cols := range gazetteerLines(gazetteerCities, 5) {
+		lat, errLat := strconv.ParseFloat(cols[2], 64)
+		lon, errLon := strconv.ParseFloat(cols[3], 64)
+		if errLat != nil || errLon != nil || countryNames[cols[1]] == "" {
+			panic(fmt.Sprintf("finder: invalid gazetteer city %q", strings.Join(cols, "|")))
+		}
+		gazetteerCityList = append(gazetteerCityList, gazetteerEntry{
+			place:   Place{City: strings.TrimPrefix(cols[0], "="), Country: cols[1], Lat: lat, Lon: lon},
+			aliases: placeAliases(cols[0] + ";" + cols[4]),
+		})
+	}
+}
+
+func gazetteerLines(data string, columns int) [][]string {
+	res := [][]string{}
+	for _, line := range strings.Split(data, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		cols := strings.Split(line, "|")
+		if len(cols) != columns {
+			panic(fmt.Sprintf("finder: invalid gazetteer line %q", line))
+		}
+		res = append(res, cols)
+	}
+	return res
+}
+
+func placeAliases(list string) []placeAlias {
+	res := []placeAlias{}
+	for _, a := range strings.Split(list, ";") {
+		if a = strings.TrimSpace(a); a == "" {
+			continue
+		}
+		alias := placeAlias{text: a}
+		switch {
+		case strings.HasPrefix(a, "="):
+			alias = placeAlias{text: a[1:], exact: true}
+		case len(a) <= 3 && strings.ToUpper(a) == a:
+			alias.exact = true
+		default:
+			alias.text = normalize(a)
+		}
+		for _, r := range alias.text {
+			if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Thai) {
+				alias.inWord = true
+				break
+			}
+		}
+		res = append(res, alias)
+	}
+	return res
+}
+
+// placeMatch : gazetteer entry found in the location
+type placeMatch struct {
+	place    Place
+	pos, len int
+}
+
+// find : the longest alias of the entry found in the location
+func (e gazetteerEntry) find(original, normalized string) (placeMatch, bool) {
+	best := placeMatch{place: e.place, pos: -1}
+	for _, a := range e.aliases {
+		text := normalized
+		if a.exact {
+			text = original
+		}
+		idx := -1
+		if a.inWord {
+			idx = strings.Index(text, a.text)
+		} else {
+			idx = boundedIndex(text, a.text, true)
+		}
+		if idx >= 0 && len(a.text) > best.len {
+			best.pos, best.len = idx, len(a.text)
+		}
+	}
+	return best, best.pos >= 0
+}
+
+// resolvePlace : resolve the free text location e.g. "Kreuzberg", "BER", "Deutschland 🇩🇪", "Berlin, NH"
+// the country hints (country names, flags, states) decide between the cities with the same name,
+// if no city is found in the hinted country the place is the country only.
+func resolvePlace(location string) Place {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return Place{}
+	}
+	normalized := normalize(location)
+
+	hints := []placeMatch{}
+	for _, e := range gazetteerCountryList {
+		if m, ok := e.find(location, normalized); ok {
+			hints = append(hints, m)
+		}
+	}
+	for _, e := range gazetteerRegionList {
+		if m, ok := e.find(location, normalized); ok {
+			hints = append(hints, m)
+		}
+	}
+	for i, code := range flagCountries(location) {
+		if countryNames[code] != "" {
+			hints = append(hints, placeMatch{place: Place{Country: code}, pos: len(location) + i})
+		}
+	}
+
+	cities := []placeMatch{}
+	for _, e := range gazetteerCityList {
+		if m, ok := e.find(location, normalized); ok {
+			cities = append(cities, m)
+		}
+	}
+
+	// "City, XX" the code is the city country, or a state/province, or a country
+	if m := trailingCodeRegex.FindStringSubmatchIndex(location); m != nil {
+		code, pos := location[m[2]:m[3]], m[2]
+		// the code is not a city alias e.g. "Baton Rouge, LA"
+		filtered := cities[:0]
+		for _, c := range cities {
+			if c.pos != pos {
+				filtered = append(filtered, c)
+			}
+		}
+		cities = filtered
+		switch {
+		case containsCountry(cities, code):
+			hints = append(hints, placeMatch{place: Place{Country: code}, pos: pos})
+		case len(regionCountries[code]) > 0:
+			for _, c := range regionCountries[code] {
+				hints = append(hints, placeMatch{place: Place{Region: code, Country: c}, pos: pos})
+			}
+		case countryNames[code] != "":
+			hints = append(hints, placeMatch{place: Place{Country: code}, pos: pos})
+		}
+	}
+
+	if len(hints) > 0 {
+		inHints := []placeMatch{}
+		for _, c := range cities {
+			if containsCountry(hints, c.place.Country) {
+				inHints = append(inHints, c)
+			}
+		}
+		if len(inHints) == 0 {
+			return firstMatch(hints).place
+		}
+		cities = inHints
+	}
+	if len(cities) > 0 {
+		return longestMatch(cities).place
+	}
+	return Place{}
+}
+
+func containsCountry(matches []placeMatch, code string) bool {
+	for _, m := range matches {
+		if m.place.Country == code {
+			return true
+		}
+	}
+	return false
+}
+
+// firstMatch : the first match in the location, states before countries at the same position
+func firstMatch(matches []placeMatch) placeMatch {
+	best := matches[0]
+	for _, m := range matches[1:] {
+		if m.pos < best.pos || (m.pos == best.pos && m.place.Region != "" && best.place.Region == "") {
+			best = m
+		}
+	}
+	return best
+}
+
+// longestMatch : the longest alias, the first in the location if equal
+func longestMatch(matches []placeMatch) placeMatch {
+	best := matches[0]
+	for _, m := range matches[1:] {
+		if m.len > best.len || (m.len == best.len && m.pos < best.pos) {
+			best = m
+		}
+	}
+	return best
+}
+
+// flagCountries : country codes of the flag emojis e.g. 🇩🇪 is "DE"
+func flagCountries(text string) []string {
+	res := []string{}
+	runes := []rune(text)
+	for i := 0; i+1 < len(runes); i++ {
+		a, b := runes[i], runes[i+1]
+		if isRegionalIndicator(a) && isRegionalIndicator(b) {
+			res = append(res, string([]rune{'A' + a - 0x1F1E6, 'A' + b - 0x1F1E6}))
+			i++
+		}
+	}
+	return res
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// parsePlace : gazetteer place or coordinates "LAT,LON" used as the center of the radius search
+func parsePlace(s string) (Place, error) {
+	if parts := strings.Split(s, ","); len(parts) == 2 {
+		lat, errLat := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		lon, errLon := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if errLat == nil && errLon == nil {
+			if math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+				return Place{}, fmt.Errorf("invalid coordinates %q", s)
+			}
+			return Place{City: fmt.Sprintf("%v,%v", lat, lon), Lat: lat, Lon: lon}, nil
+		}
+	}
+	p := resolvePlace(s)
+	if !p.hasCoordinates() {
+		return Place{}, fmt.Errorf("unknown city %q", s)
+	}
+	return p, nil
+}
+
+// parseCountry : validate the country code e.g. "de"
+func parseCountry(s string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if countryNames[code] == "" {
+		return "", fmt.Errorf("unknown country code %q", s)
+	}
+	return code, nil
+}
+
+// userPlace : resolved location of the user
+func userPlace(u *profile) Place {
+	return u.placeOf()
+}
@@ -0,0 +1,333 @@
+package finder
+
+// gazetteer data, one entry per line, aliases are separated by ';'
+//   - upper case aliases up to 3 letters (e.g. BER, NYC) and aliases starting with '=' are case sensitive
+//   - the other aliases are compared after normalize, e.g. "Köln" match "koln"
+
+// gazetteerCountries : CODE|NAME|ALIASES (ISO 3166-1 alpha-2)
+const gazetteerCountries = `
+DE|Germany|Deutschland;Allemagne;Alemania;Germania;ألمانيا
+AT|Austria|Österreich;Oesterreich;النمسا
+CH|Switzerland|Schweiz;Suisse;Svizzera;سويسرا
+GB|United Kingdom|UK;=U.K.;Great Britain;Britain;England;Scotland;Wales;Northern Ireland;بريطانيا;المملكة المتحدة
+IE|Ireland|Éire
+FR|France|فرنسا
+ES|Spain|España;إسبانيا
+PT|Portugal|
+IT|Italy|Italia;إيطاليا
+NL|Netherlands|Nederland;Holland;هولندا
+BE|Belgium|België;Belgique
+DK|Denmark|Danmark
+SE|Sweden|Sverige
+NO|Norway|Norge
+FI|Finland|Suomi
+PL|Poland|Polska
+CZ|Czechia|Czech Republic;Česko
+HU|Hungary|Magyarország
+GR|Greece|Ελλάδα;Hellas
+TR|Turkey|Türkiye;تركيا
+RU|Russia|Россия;روسيا
+UA|Ukraine|Україна
+EG|Egypt|مصر;Misr
+SA|Saudi Arabia|KSA;السعودية;المملكة العربية السعودية
+AE|United Arab Emirates|UAE;الإمارات
+QA|Qatar|قطر
+KW|Kuwait|الكويت
+BH|Bahrain|البحرين
+OM|Oman|عمان
+JO|Jordan|الأردن
+LB|Lebanon|لبنان
+SY|Syria|سوريا
+IQ|Iraq|العراق
+MA|Morocco|Maroc;المغرب
+TN|Tunisia|Tunisie;تونس
+DZ|Algeria|Algérie;الجزائر
+LY|Libya|ليبيا
+SD|Sudan|السودان
+YE|Yemen|اليمن
+PS|Palestine|فلسطين
+IL|Israel|ישראל
+IR|Iran|ایران;إيران
+PK|Pakistan|پاکستان
+IN|India|Bharat;भारत
+CN|China|中国
+HK|Hong Kong|香港
+TW|Taiwan|台灣;台湾
+JP|Japan|日本
+KR|South Korea|Korea;대한민국;한국
+SG|Singapore|
+ID|Indonesia|
+MY|Malaysia|
+TH|Thailand|ประเทศไทย
+PH|Philippines|
+VN|Vietnam|Viet Nam
+AU|Australia|
+NZ|New Zealand|Aotearoa
+US|United States|USA;=US;=U.S.;=U.S.A.;America;United States of America;أمريكا
+CA|Canada|
+MX|Mexico|México
+BR|Brazil|Brasil
+AR|Argentina|
+CO|Colombia|
+CL|Chile|
+PE|Peru|Perú
+NG|Nigeria|
+KE|Kenya|
+ZA|South Africa|
+ET|Ethiopia|
+`
+
+// gazetteerRegions : COUNTRY|CODE|NAME, states and provinces used to tell "Berlin, NH" from Berlin, DE
+const gazetteerRegions = `
+US|AL|Alabama
+US|AK|Alaska
+US|AZ|Arizona
+US|AR|Arkansas
+US|CA|California
+US|CO|Colorado
+US|CT|Connecticut
+US|DE|Delaware
+US|FL|Florida
+US|GA|Georgia
+US|HI|Hawaii
+US|ID|Idaho
+US|IL|Illinois
+US|IN|Indiana
+US|IA|Iowa
+US|KS|Kansas
+US|KY|Kentucky
+US|LA|Louisiana
+US|ME|Maine
+US|MD|Maryland
+US|MA|Massachusetts
+US|MI|Michigan
+US|MN|Minnesota
+US|MS|Mississippi
+US|MO|Missouri
+US|MT|Montana
+US|NE|Nebraska
+US|NV|Nevada
+US|NH|New Hampshire
+US|NJ|New Jersey
+US|NM|New Mexico
+US|NY|New York
+US|NC|North Carolina
+US|ND|North Dakota
+US|OH|Ohio
+US|OK|Oklahoma
+US|OR|Oregon
+US|PA|Pennsylvania
+US|RI|Rhode Island
+US|SC|South Carolina
+US|SD|South Dakota
+US|TN|Tennessee
+US|TX|Texas
+US|UT|Utah
+US|VT|Vermont
+US|VA|Virginia
+US|WA|Washington
+US|WV|West Virginia
+US|WI|Wisconsin
+US|WY|Wyoming
+CA|ON|Ontario
+CA|QC|Quebec
+CA|BC|British Columbia
+CA|AB|Alberta
+`
+
+// gazetteerCities : NAME|COUNTRY|LAT|LON|ALIASES
+const gazetteerCities = `
+Berlin|DE|52.520|13.405|BER;TXL;Kreuzberg;Friedrichshain;Neukölln;Prenzlauer Berg;Charlottenburg;Schöneberg;Moabit;Pankow;Spandau;Steglitz
+Hamburg|DE|53.551|9.994|HAM;St. Pauli;Altona
+Munich|DE|48.137|11.575|München;Muenchen;MUC;Schwabing
+Cologne|DE|50.938|6.960|Köln;Koeln;CGN
+Frankfurt|DE|50.110|8.682|Frankfurt am Main;FRA
+Stuttgart|DE|48.776|9.183|
+Düsseldorf|DE|51.227|6.773|Duesseldorf;DUS
+Leipzig|DE|51.340|12.375|
+Dresden|DE|51.050|13.738|
+Hanover|DE|52.375|9.732|Hannover
+Nuremberg|DE|49.452|11.077|Nürnberg;Nuernberg
+Bremen|DE|53.079|8.802|
+Bonn|DE|50.737|7.098|
+Karlsruhe|DE|49.007|8.404|
+Potsdam|DE|52.390|13.065|
+Vienna|AT|48.208|16.373|Wien;VIE
+Graz|AT|47.070|15.440|
+Linz|AT|48.306|14.286|
+Salzburg|AT|47.810|13.055|
+Innsbruck|AT|47.269|11.404|
+Zurich|CH|47.377|8.541|Zürich;Zuerich;ZRH
+Geneva|CH|46.204|6.143|Genève;Genf;GVA
+Basel|CH|47.560|7.590|
+Bern|CH|46.948|7.447|Berne
+Lausanne|CH|46.520|6.633|
+London|GB|51.507|-0.128|LDN;LHR;Shoreditch;Camden;Hackney;Brixton
+Manchester|GB|53.480|-2.242|MCR
+Birmingham|GB|52.486|-1.890|
+Edinburgh|GB|55.953|-3.189|
+Glasgow|GB|55.864|-4.252|
+Liverpool|GB|53.408|-2.992|
+Bristol|GB|51.455|-2.588|
+Leeds|GB|53.800|-1.549|
+Cambridge|GB|52.205|0.119|
+Oxford|GB|51.752|-1.258|
+Belfast|GB|54.597|-5.930|
+Cardiff|GB|51.481|-3.179|
+Dublin|IE|53.350|-6.260|
+Paris|FR|48.857|2.352|CDG;Montmartre
+Lyon|FR|45.764|4.836|
+Marseille|FR|43.296|5.370|Marseilles
+Toulouse|FR|43.605|1.444|
+=Nice|FR|43.710|7.262|
+Bordeaux|FR|44.838|-0.579|
+Lille|FR|50.629|3.057|
+Nantes|FR|47.218|-1.554|
+Strasbourg|FR|48.573|7.752|
+Madrid|ES|40.417|-3.704|
+Barcelona|ES|41.385|2.173|BCN
+Valencia|ES|39.470|-0.376|
+Seville|ES|37.389|-5.984|Sevilla
+Bilbao|ES|43.263|-2.935|
+Málaga|ES|36.721|-4.421|
+Lisbon|PT|38.722|-9.139|Lisboa
+Porto|PT|41.158|-8.629|Oporto
+Rome|IT|41.903|12.496|Roma
+Milan|IT|45.464|9.190|Milano
+Naples|IT|40.852|14.268|Napoli
+Turin|IT|45.070|7.687|Torino
+Florence|IT|43.770|11.256|Firenze
+Bologna|IT|44.494|11.343|
+Venice|IT|45.441|12.316|Venezia
+Amsterdam|NL|52.368|4.904|AMS
+Rotterdam|NL|51.924|4.478|
+The Hague|NL|52.070|4.300|Den Haag
+Utrecht|NL|52.090|5.121|
+Eindhoven|NL|51.441|5.478|
+Brussels|BE|50.850|4.352|Bruxelles;Brussel
+Antwerp|BE|51.219|4.402|Antwerpen
+Ghent|BE|51.054|3.717|Gent
+Copenhagen|DK|55.676|12.568|København
+Stockholm|SE|59.329|18.069|
+Gothenburg|SE|57.709|11.975|Göteborg
+Malmö|SE|55.605|13.000|
+Oslo|NO|59.914|10.752|
+Helsinki|FI|60.170|24.938|
+Warsaw|PL|52.230|21.012|Warszawa
+Kraków|PL|50.065|19.945|Cracow
+Wrocław|PL|51.108|17.039|
+Prague|CZ|50.075|14.438|Praha;Prag
+Budapest|HU|47.498|19.040|
+Athens|GR|37.984|23.728|Athina;Αθήνα
+Istanbul|TR|41.008|28.978|İstanbul;إسطنبول
+Ankara|TR|39.934|32.860|
+Izmir|TR|38.423|27.143|İzmir
+Moscow|RU|55.756|37.617|Москва;Moskva;Moskau
+Saint Petersburg|RU|59.939|30.316|St Petersburg;St. Petersburg;Санкт-Петербург
+Kyiv|UA|50.450|30.523|Kiev;Київ;Киев
+Cairo|EG|30.044|31.236|القاهرة
+Alexandria|EG|31.200|29.918|الإسكندرية
+Giza|EG|30.013|31.209|الجيزة
+Riyadh|SA|24.713|46.675|الرياض
+Jeddah|SA|21.543|39.173|Jiddah;جدة
+Mecca|SA|21.389|39.858|Makkah;مكة
+Medina|SA|24.467|39.600|Madinah;المدينة المنورة
+Dammam|SA|26.392|49.978|الدمام
+Dubai|AE|25.205|55.271|دبي
+Abu Dhabi|AE|24.453|54.377|أبوظبي;أبو ظبي
+Sharjah|AE|25.346|55.421|الشارقة
+Doha|QA|25.285|51.531|الدوحة
+Kuwait City|KW|29.376|47.977|مدينة الكويت
+Manama|BH|26.228|50.586|المنامة
+Muscat|OM|23.588|58.383|مسقط
+Amman|JO|31.954|35.911|
+Beirut|LB|33.894|35.502|بيروت
+Damascus|SY|33.513|36.292|دمشق
+Baghdad|IQ|33.315|44.366|بغداد
+Casablanca|MA|33.573|-7.590|الدار البيضاء
+Rabat|MA|34.021|-6.841|الرباط
+Marrakesh|MA|31.630|-8.008|Marrakech;مراكش
+Tunis|TN|36.806|10.181|
+Algiers|DZ|36.754|3.059|Alger
+Tripoli|LY|32.887|13.191|طرابلس
+Khartoum|SD|15.500|32.560|الخرطوم
+Sanaa|YE|15.369|44.191|صنعاء
+Gaza|PS|31.500|34.467|غزة
+Ramallah|PS|31.903|35.204|رام الله
+Tel Aviv|IL|32.085|34.782|תל אביב
+Jerusalem|IL|31.768|35.214|ירושלים;القدس
+Tehran|IR|35.689|51.389|Teheran;تهران
+Karachi|PK|24.861|67.010|کراچی
+Lahore|PK|31.520|74.359|لاہور
+Islamabad|PK|33.684|73.048|اسلام آباد
+Mumbai|IN|19.076|72.878|Bombay
+Delhi|IN|28.704|77.102|New Delhi
+Bangalore|IN|12.972|77.595|Bengaluru
+Hyderabad|IN|17.385|78.487|
+Chennai|IN|13.083|80.270|Madras
+Kolkata|IN|22.573|88.364|Calcutta
+Pune|IN|18.520|73.857|
+Beijing|CN|39.904|116.407|Peking;北京
+Shanghai|CN|31.230|121.474|上海
+Shenzhen|CN|22.543|114.058|深圳
+Hong Kong|HK|22.319|114.169|香港
+Taipei|TW|25.033|121.565|台北
+Tokyo|JP|35.680|139.760|東京
+Osaka|JP|34.694|135.502|大阪
+Kyoto|JP|35.012|135.768|京都
+Seoul|KR|37.567|126.978|서울
+Singapore|SG|1.352|103.820|
+Jakarta|ID|-6.208|106.846|
+Kuala Lumpur|MY|3.139|101.687|
+Bangkok|TH|13.756|100.502|กรุงเทพ
+Manila|PH|14.600|120.984|
+Ho Chi Minh City|VN|10.823|106.630|Saigon
+Hanoi|VN|21.028|105.834|
+Sydney|AU|-33.869|151.209|
+Melbourne|AU|-37.814|144.963|
+Brisbane|AU|-27.470|153.026|
+Perth|AU|-31.950|115.861|
+Auckland|NZ|-36.848|174.763|
+New York|US|40.713|-74.006|NYC;New York City;Brooklyn;Manhattan;Queens
+San Francisco|US|37.775|-122.419|SF;SFO;Bay Area
+Los Angeles|US|34.052|-118.244|LA;LAX
+Chicago|US|41.878|-87.630|
+Boston|US|42.360|-71.059|
+Seattle|US|47.606|-122.332|
+Austin|US|30.267|-97.743|
+Washington, D.C.|US|38.907|-77.037|Washington DC;Washington D.C.;Washington, DC;DC
+Miami|US|25.762|-80.192|
+Atlanta|US|33.749|-84.388|
+Denver|US|39.739|-104.990|
+Dallas|US|32.777|-96.797|
+Houston|US|29.760|-95.370|
+Philadelphia|US|39.953|-75.165|Philly
+San Diego|US|32.716|-117.161|
+Portland|US|45.515|-122.679|
+San Jose|US|37.339|-121.895|
+Palo Alto|US|37.442|-122.143|
+Mountain View|US|37.386|-122.084|
+Silicon Valley|US|37.387|-122.060|
+Las Vegas|US|36.170|-115.140|
+Detroit|US|42.331|-83.046|
+Minneapolis|US|44.978|-93.265|
+Nashville|US|36.163|-86.781|
+Toronto|CA|43.653|-79.383|
+Montreal|CA|45.502|-73.567|Montréal
+Vancouver|CA|49.283|-123.121|
+Ottawa|CA|45.421|-75.697|
+Calgary|CA|51.045|-114.072|
+Mexico City|MX|19.433|-99.133|CDMX;Ciudad de México
+São Paulo|BR|-23.551|-46.633|
+Rio de Janeiro|BR|-22.907|-43.173|
+Buenos Aires|AR|-34.604|-58.382|
+Bogotá|CO|4.711|-74.072|
+Santiago|CL|-33.449|-70.669|
+Lima|PE|-12.046|-77.043|
+Lagos|NG|6.524|3.379|
+Abuja|NG|9.076|7.399|
+Nairobi|KE|-1.292|36.822|
+Johannesburg|ZA|-26.204|28.047|Joburg
+Cape Town|ZA|-33.925|18.424|
+Addis Ababa|ET|9.030|38.740|
+`
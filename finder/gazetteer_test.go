@@ -0,0 +1,91 @@
+package finder
+
+import (
+	"testing"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+func TestResolvePlace(t *testing.T) {
+	tests := []struct {
+		location string
+		city     string
+		region   string
+		country  string
+	}{
+		// aliases of the city
+		{"Berlin", "Berlin", "", "DE"},
+		{"BER", "Berlin", "", "DE"},
+		{"Kreuzberg", "Berlin", "", "DE"},
+		{"Living in Kreuzberg ✨", "Berlin", "", "DE"},
+		{"Berlin, Germany", "Berlin", "", "DE"},
+		// the country only
+		{"Deutschland 🇩🇪", "", "", "DE"},
+		{"🇩🇪", "", "", "DE"},
+		{"Germany", "", "", "DE"},
+		// the state hints another country than the city
+		{"Berlin, NH", "", "NH", "US"},
+		{"Potsdam", "Potsdam", "", "DE"},
+		// unknown
+		{"", "", "", ""},
+		{"somewhere over the rainbow", "", "", ""},
+	}
+	for _, tt := range tests {
+		p := resolvePlace(tt.location)
+		if p.City != tt.city || p.Region != tt.region || p.Country != tt.country {
+			t.Errorf("%q resolved to %+v, expected %v %v %v", tt.location, p, tt.city, tt.region, tt.country)
+		}
+		if p.hasCoordinates() != (tt.city != "") {
+			t.Errorf("%q coordinates %v, %v", tt.location, p.Lat, p.Lon)
+		}
+	}
+}
+
+func TestLocationCriteria(t *testing.T) {
+	recordErrors(t)
+	tests := []struct {
+		name     string
+		criteria config.SearchCriteria
+		matched  map[string]bool
+	}{
+		{"country", config.SearchCriteria{LocationCountries: []string{"de"}}, map[string]bool{
+			"Kreuzberg": true, "Deutschland 🇩🇪": true, "Potsdam": true, "Hamburg": true, "Berlin, NH": false, "": false,
+		}},
+		{"near:Berlin~50", config.SearchCriteria{Query: "near:Berlin~50"}, map[string]bool{
+			"Kreuzberg": true, "BER": true, "Potsdam": true, "Hamburg": false, "Berlin, NH": false,
+			// the country only has no coordinates
+			"Deutschland 🇩🇪": false,
+		}},
+		{"LOCATION_NEAR", config.SearchCriteria{LocationNear: config.LocationNear{Place: "Berlin", Km: 50}}, map[string]bool{
+			"Potsdam": true, "Hamburg": false,
+		}},
+		{"near:Berlin~300", config.SearchCriteria{Query: "near:Berlin~300"}, map[string]bool{
+			"Potsdam": true, "Hamburg": true,
+		}},
+	}
+	for _, tt := range tests {
+		f, err := NewFinder(tt.criteria)
+		if err != nil {
+			t.Errorf("%v: %v", tt.name, err)
+			continue
+		}
+		for location, matched := range tt.matched {
+			u := anaconda.User{Location: location}
+			if report := f.Match(&u); report.Matched != matched {
+				t.Errorf("%v: %q matched %v, expected %v: %v", tt.name, location, report.Matched, matched, report)
+			}
+		}
+	}
+
+	for _, criteria := range []config.SearchCriteria{
+		{Query: "near:Atlantis~50"},
+		{Query: "near:Berlin~0"},
+		{LocationNear: config.LocationNear{Place: "Berlin"}},
+		{LocationCountries: []string{"XX"}},
+	} {
+		if _, err := NewFinder(criteria); err == nil {
+			t.Errorf("%+v accepted", criteria)
+		}
+	}
+}
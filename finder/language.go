@@ -0,0 +1,279 @@
+package finder
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// offline language detection,
+// the script decides the language for most of the non latin languages,
+// latin texts are compared with the trigram profiles built from languageCorpus.
+
+// minLanguageLetters : shorter texts are not detected
+const minLanguageLetters = 8
+
+// defaultLanguageConfidence : the minimum confidence of the language criteria if LANGUAGE_CONFIDENCE is not set
+const defaultLanguageConfidence = 0.3
+
+// Language : detected language of the user profile
+type Language struct {
+	// Code : ISO 639-1 code e.g. "en", "de", "ar", empty if unknown
+	Code string `json:"CODE"`
+	// Confidence : 0 to 1
+	Confidence float64 `json:"CONFIDENCE"`
+}
+
+// scriptLanguages : languages decided by the script alone
+var scriptLanguages = []struct {
+	code   string
+	script *unicode.RangeTable
+}{
+	{"el", unicode.Greek},
+	{"he", unicode.Hebrew},
+	{"hi", unicode.Devanagari},
+	{"th", unicode.Thai},
+	{"ko", unicode.Hangul},
+}
+
+// scriptLetters : letters that tell the languages of the same script apart,
+// checked in order, the last one (without letters) is the default.
+var scriptLetters = map[string][]struct {
+	code    string
+	letters string
+}{
+	"arabic":   {{"ur", "ٹڈڑںےۓھ"}, {"fa", "پچژگیک"}, {"ar", ""}},
+	"cyrillic": {{"uk", "іїєґІЇЄҐ"}, {"ru", ""}},
+}
+
+// trigramProfile : log probability of the trigrams in the language corpus
+type trigramProfile struct {
+	logProb map[string]float64
+	// unseen : log probability of the trigrams that are not in the corpus
+	unseen float64
+}
+
+// trigramProfiles : the profiles of the latin script languages
+var trigramProfiles = map[string]trigramProfile{}
+
+// corpusTrigrams : the trigrams found in any corpus, the others tell nothing about the language
+var corpusTrigrams = map[string]bool{}
+
+// minLanguageMargin : the probability of the closest language should exceed the next one by this margin at least,
+// the text is unknown otherwise e.g. the technical words that are shared by the languages.
+const minLanguageMargin = 0.25
+
+// maxTrigramEvidence : the trigrams counted in the confidence at most,
+// the confidence of long texts is not pushed to 1 by the number of trigrams alone.
+const maxTrigramEvidence = 8
+
+// SupportedLanguages : all the language codes the detector can return
+var SupportedLanguages = []string{"ja", "zh"}
+
+func init() {
+	counts := map[string]map[string]float64{}
+	for code, corpus := range languageCorpus {
+		counts[code] = trigrams(corpus)
+		for g := range counts[code] {
+			corpusTrigrams[g] = true
+		}
+		SupportedLanguages = append(SupportedLanguages, code)
+	}
+	for code, c := range counts {
+		trigramProfiles[code] = newTrigramProfile(c, len(corpusTrigrams))
+	}
+	for _, s := range scriptLanguages {
+		SupportedLanguages = append(SupportedLanguages, s.code)
+	}
+	for _, letters := range scriptLetters {
+		for _, l := range letters {
+			SupportedLanguages = append(SupportedLanguages, l.code)
+		}
+	}
+	sort.Strings(SupportedLanguages)
+}
+
+// isSupportedLanguage : check the language code
+func isSupportedLanguage(code string) bool {
+	for _, c := range SupportedLanguages {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// detectLanguage : detect the language of the text
+// the confidence is the share of the letters in the language script,
+// for the trigram languages multiplied by the probability of the language.
+func detectLanguage(text string) Language {
+	text = stripEntities(text)
+	counts := map[string]int{}
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		counts[letterScript(r)]++
+	}
+	if letters < minLanguageLetters {
+		return Language{}
+	}
+	script, best := "", 0
+	for s, c := range counts {
+		if c > best || (c == best && s < script) {
+			script, best = s, c
+		}
+	}
+	share := float64(best) / float64(letters)
+	switch script {
+	case "latin":
+		code, probability := closestProfile(text)
+		return Language{Code: code, Confidence: round2(share * probability)}
+	case "arabic", "cyrillic":
+		for _, l := range scriptLetters[script] {
+			if l.letters == "" || strings.ContainsAny(text, l.letters) {
+				return Language{Code: l.code, Confidence: round2(share)}
+			}
+		}
+	case "kana":
+		return Language{Code: "ja", Confidence: round2(share)}
+	case "han":
+		// japanese texts mix han with kana
+		if counts["kana"] > 0 {
+			return Language{Code: "ja", Confidence: round2(float64(best+counts["kana"]) / float64(letters))}
+		}
+		return Language{Code: "zh", Confidence: round2(share)}
+	}
+	for _, l := range scriptLanguages {
+		if l.code == script {
+			return Language{Code: l.code, Confidence: round2(share)}
+		}
+	}
+	return Language{}
+}
+
+// letterScript : script name of the letter, the script languages use the language code
+func letterScript(r rune) string {
+	switch {
+	case r < 0x250 || unicode.Is(unicode.Latin, r):
+		return "latin"
+	case unicode.Is(unicode.Cyrillic, r):
+		return "cyrillic"
+	case unicode.Is(unicode.Arabic, r):
+		return "arabic"
+	case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+		return "kana"
+	case unicode.Is(unicode.Han, r):
+		return "han"
+	}
+	for _, l := range scriptLanguages {
+		if unicode.Is(l.script, r) {
+			return l.code
+		}
+	}
+	return "other"
+}
+
+// newTrigramProfile : additive smoothing of the trigram counts over the vocabulary of all the corpora
+func newTrigramProfile(counts map[string]float64, vocabulary int) trigramProfile {
+	const alpha = 0.5
+	total := 0.0
+	for _, c := range counts {
+		total += c
+	}
+	denominator := total + alpha*float64(vocabulary)
+	p := trigramProfile{logProb: make(map[string]float64, len(counts)), unseen: math.Log(alpha / denominator)}
+	for g, c := range counts {
+		p.logProb[g] = math.Log((c + alpha) / denominator)
+	}
+	return p
+}
+
+// closestProfile : the language with the most likely trigrams and its probability (0 to 1),
+// the probabilities are compared per trigram, for up to maxTrigramEvidence trigrams,
+// empty if the closest languages are too close to tell apart (minLanguageMargin).
+func closestProfile(text string) (string, float64) {
+	t := trigrams(text)
+	n := 0.0
+	for g, c := range t {
+		if corpusTrigrams[g] {
+			n += c
+		}
+	}
+	if n == 0 {
+		return "", 0
+	}
+	scores := make(map[string]float64, len(trigramProfiles))
+	code, best := "", math.Inf(-1)
+	for c, p := range trigramProfiles {
+		score := 0.0
+		for g, count := range t {
+			if !corpusTrigrams[g] {
+				continue
+			}
+			lp, ok := p.logProb[g]
+			if !ok {
+				lp = p.unseen
+			}
+			score += count * lp
+		}
+		score /= n
+		scores[c] = score
+		if score > best || (score == best && c < code) {
+			code, best = c, score
+		}
+	}
+	evidence := math.Min(n, maxTrigramEvidence)
+	sum, second := 0.0, 0.0
+	for c, score := range scores {
+		p := math.Exp(evidence * (score - best))
+		sum += p
+		if c != code && p > second {
+			second = p
+		}
+	}
+	if (1-second)/sum < minLanguageMargin {
+		return "", 0
+	}
+	return code, 1 / sum
+}
+
+// trigrams : trigram counts of the words, the words are padded with a space
+func trigrams(text string) map[string]float64 {
+	res := map[string]float64{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		runes := []rune(" " + w + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			res[string(runes[i:i+3])]++
+		}
+	}
+	return res
+}
+
+// stripEntities : remove the urls, mentions, hashtags and emails, they are not language specific
+func stripEntities(text string) string {
+	for _, re := range []*regexp.Regexp{urlRegex, emailRegex, mentionRegex, hashtagRegex} {
+		text = re.ReplaceAllString(text, " ")
+	}
+	return text
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// userLanguageText : the texts used to detect the user language
+func userLanguageText(u *anaconda.User) string {
+	return u.Description + "\n" + u.Name
+}
+
+// userLanguage : detected language of the user bio and name
+func userLanguage(u *profile) Language {
+	return u.languageOf()
+}
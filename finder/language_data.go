@@ -0,0 +1,252 @@
+package finder
+
+// language data, the trigram profiles of the latin script languages are built from these texts,
+// they mix profile bios with everyday sentences so that the short bios and the common words are covered.
+
+// languageCorpus : the text of every latin script language by ISO 639-1 code
+var languageCorpus = map[string]string{
+	"en": `I am a software engineer and the founder of a small company. We build tools for people who love
+		open source. Father of two, coffee lover and runner. All opinions are my own and not those of my employer.
+		Writing about technology, startups, design and the future of work. Previously at a bank, now working with
+		the best team in the world. Tweets about music, books and whatever I find interesting this week.
+		Living in the city with my wife and our dog. Host of a weekly podcast where we talk with makers.
+		Mother, teacher and proud member of the local community. She loves travel, photography and good food.
+		Helping small businesses grow online since 2010. Views are mine, retweets are not endorsements.
+		Student of history, politics and economics at the university. Football fan, always hoping for next season.
+		The weather was nice yesterday, so we went to the park with the children and had lunch by the river.
+		He said that they would come back later, but nobody knew when the train was going to arrive.
+		What do you think about the new policy? I think it could be better if everyone had a chance to speak.
+		There is nothing more important than health, family and friends who are there when you need them.
+		Journalist covering science and the environment. Author of three books. Marketing manager, dreamer,
+		gamer and cat person. Husband and dad. Building things that people actually use. Just trying to learn
+		something new every day and share it with you. Sharing my thoughts on life, love and everything else.
+		Nurse, volunteer and lover of long walks. Making the internet a better place, one line of code at a time.
+		Full stack developer and data scientist with a PhD in machine learning. Senior backend engineer, frontend
+		developer, product manager and designer. CTO and co-founder, ex-Google, angel investor and advisor to early
+		stage startups. Working on cloud, security, infrastructure, mobile and web development. Speaker, mentor and
+		writer. Software developer who writes Python, JavaScript and Go. Research scientist at the lab, professor of
+		computer science, analyst, consultant and entrepreneur. Chief technology officer, head of engineering and
+		staff engineer. Investing in founders who build the future. Building developer tools and platforms at scale.
+		Passionate about data, artificial intelligence, deep learning and open science. Lawyer, doctor, artist,
+		photographer and freelance writer. We are hiring engineers, come and join us. Digital marketing specialist,
+		growth hacker and content creator. Former athlete, now coaching the next generation.
+		Gopher and Rustacean, writing Golang and Rust on Kubernetes, Docker and Linux. DevOps, SRE and platform
+		engineering, on call for the clusters. Maintainer of open source libraries, contributor to the compiler and
+		the runtime. Frontend with React and TypeScript, backend with Java, Kotlin and Node. Data engineering with
+		SQL, Spark and Kafka. Machine learning engineer working on models, pipelines and the training data.`,
+	"de": `Ich bin Softwareentwickler und Gründer eines kleinen Unternehmens. Wir bauen Werkzeuge für Menschen,
+		die Open Source lieben. Vater von zwei Kindern, Kaffeeliebhaber und Läufer. Hier schreibe ich meine eigene
+		Meinung und nicht die meines Arbeitgebers. Ich schreibe über Technologie, Startups, Design und die Zukunft
+		der Arbeit. Früher bei einer Bank, jetzt mit dem besten Team der Welt. Tweets über Musik, Bücher und alles,
+		was ich diese Woche interessant finde. Lebe mit meiner Frau und unserem Hund in der Stadt. Gastgeber eines
+		wöchentlichen Podcasts, in dem wir mit Machern sprechen. Mutter, Lehrerin und stolzes Mitglied unserer
+		Gemeinde. Sie liebt Reisen, Fotografie und gutes Essen. Ich helfe kleinen Firmen seit 2010 beim Wachsen im
+		Internet. Das ist meine private Meinung, Retweets sind keine Zustimmung. Student der Geschichte, Politik
+		und Wirtschaft an der Universität. Fußballfan, immer voller Hoffnung auf die nächste Saison. Gestern war
+		das Wetter schön, also sind wir mit den Kindern in den Park gegangen und haben am Fluss gegessen. Er sagte,
+		dass sie später zurückkommen würden, aber niemand wusste, wann der Zug ankommen sollte. Was denkst du über
+		die neue Regel? Ich glaube, es wäre besser, wenn jeder die Möglichkeit hätte, etwas zu sagen. Es gibt nichts
+		Wichtigeres als Gesundheit, Familie und Freunde, die für dich da sind, wenn du sie brauchst. Journalistin
+		für Wissenschaft und Umwelt. Autor von drei Büchern. Ehemann und Papa. Ich versuche jeden Tag etwas Neues
+		zu lernen und es mit euch zu teilen. Krankenpfleger, Ehrenamtlicher und Liebhaber langer Spaziergänge.
+		Full-Stack-Entwickler und Datenwissenschaftler mit Doktortitel in maschinellem Lernen. Leitender
+		Ingenieur für Backend, Produktmanagerin und Designerin. Technischer Leiter und Mitgründer, früher bei einem
+		Konzern, Investor und Berater für junge Unternehmen. Ich arbeite an Cloud, Sicherheit, Infrastruktur und
+		mobiler Entwicklung. Sprecher, Mentor und Autor. Forscherin an der Hochschule, Professor für Informatik,
+		Berater und Unternehmer. Leiter der Entwicklung. Ich investiere in Gründerinnen und Gründer, die die Zukunft
+		bauen. Begeistert von Daten, künstlicher Intelligenz und offener Wissenschaft. Rechtsanwalt, Ärztin,
+		Künstlerin, Fotograf und freier Journalist. Wir suchen Entwicklerinnen und Entwickler, bewirb dich jetzt.
+		Spezialist für digitales Marketing und Inhalte. Ehemaliger Sportler, jetzt Trainer der nächsten Generation.`,
+	"fr": `Je suis ingénieur logiciel et fondateur d'une petite entreprise. Nous créons des outils pour les gens
+		qui aiment le logiciel libre. Père de deux enfants, amateur de café et coureur. Les opinions exprimées ici
+		sont les miennes et pas celles de mon employeur. J'écris sur la technologie, les startups, le design et
+		l'avenir du travail. Avant dans une banque, maintenant avec la meilleure équipe du monde. Des tweets sur la
+		musique, les livres et tout ce que je trouve intéressant cette semaine. Je vis en ville avec ma femme et
+		notre chien. Animateur d'un podcast hebdomadaire où nous parlons avec des créateurs. Mère, professeure et
+		fière membre de la communauté. Elle aime les voyages, la photographie et la bonne cuisine. J'aide les
+		petites entreprises à se développer sur internet depuis 2010. Mes propos n'engagent que moi. Étudiant en
+		histoire, en politique et en économie à l'université. Fan de football, toujours plein d'espoir pour la
+		saison prochaine. Hier il faisait beau, alors nous sommes allés au parc avec les enfants et nous avons
+		mangé au bord de la rivière. Il a dit qu'ils reviendraient plus tard, mais personne ne savait quand le
+		train allait arriver. Qu'est-ce que tu penses de la nouvelle règle? Je pense que ce serait mieux si chacun
+		pouvait parler. Il n'y a rien de plus important que la santé, la famille et les amis qui sont là quand on
+		a besoin d'eux. Journaliste scientifique et environnement. Auteur de trois livres. Mari et papa. J'essaie
+		d'apprendre quelque chose de nouveau chaque jour et de le partager avec vous. Infirmier et bénévole.
+		Développeur full stack et data scientist, docteur en apprentissage automatique. Ingénieur backend
+		senior, chef de produit et designer. Directeur technique et cofondateur, ancien de chez un grand groupe,
+		investisseur et conseiller de jeunes entreprises. Je travaille sur le cloud, la sécurité, l'infrastructure
+		et le développement mobile. Conférencier, mentor et auteur. Chercheuse au laboratoire, professeur
+		d'informatique, analyste, consultant et entrepreneur. Responsable de l'ingénierie. J'investis dans les
+		fondateurs qui construisent l'avenir. Passionné par les données, l'intelligence artificielle et la science
+		ouverte. Avocat, médecin, artiste, photographe et journaliste indépendant. Nous recrutons des développeurs,
+		rejoignez-nous. Spécialiste du marketing numérique. Ancien sportif, aujourd'hui entraîneur des jeunes.`,
+	"es": `Soy ingeniero de software y fundador de una pequeña empresa. Construimos herramientas para las
+		personas que aman el código abierto. Padre de dos hijos, amante del café y corredor. Las opiniones son
+		mías y no de mi empleador. Escribo sobre tecnología, startups, diseño y el futuro del trabajo. Antes en un
+		banco, ahora trabajando con el mejor equipo del mundo. Tuits sobre música, libros y todo lo que me parece
+		interesante esta semana. Vivo en la ciudad con mi esposa y nuestro perro. Presentador de un podcast
+		semanal donde hablamos con creadores. Madre, maestra y orgullosa miembro de la comunidad. Le encantan los
+		viajes, la fotografía y la buena comida. Ayudo a las pequeñas empresas a crecer en internet desde 2010.
+		Estudiante de historia, política y economía en la universidad. Aficionado al fútbol, siempre con la
+		esperanza puesta en la próxima temporada. Ayer hizo buen tiempo, así que fuimos al parque con los niños y
+		comimos junto al río. Él dijo que volverían más tarde, pero nadie sabía cuándo iba a llegar el tren. ¿Qué
+		piensas de la nueva norma? Creo que sería mejor si todos tuvieran la oportunidad de hablar. No hay nada
+		más importante que la salud, la familia y los amigos que están ahí cuando los necesitas. Periodista de
+		ciencia y medio ambiente. Autor de tres libros. Esposo y papá. Intento aprender algo nuevo cada día y
+		compartirlo con ustedes. Enfermero, voluntario y amante de los paseos largos. Hincha del mejor equipo.
+		Desarrollador full stack y científico de datos, doctor en aprendizaje automático. Ingeniero de backend,
+		jefa de producto y diseñadora. Director de tecnología y cofundador, inversor y asesor de empresas
+		emergentes. Trabajo en la nube, seguridad, infraestructura y desarrollo móvil. Ponente, mentor y escritor.
+		Investigadora en el laboratorio, profesor de informática, analista, consultor y emprendedor. Responsable de
+		ingeniería. Invierto en fundadores que construyen el futuro. Apasionado por los datos, la inteligencia
+		artificial y la ciencia abierta. Abogado, médica, artista, fotógrafo y periodista independiente. Estamos
+		contratando desarrolladores, únete a nosotros. Especialista en marketing digital y creador de contenido.
+		Exdeportista, ahora entrenador de la próxima generación.`,
+	"it": `Sono un ingegnere del software e il fondatore di una piccola azienda. Costruiamo strumenti per le
+		persone che amano l'open source. Padre di due figli, amante del caffè e corridore. Le opinioni sono mie e
+		non del mio datore di lavoro. Scrivo di tecnologia, startup, design e del futuro del lavoro. Prima in una
+		banca, adesso lavoro con la migliore squadra del mondo. Tweet su musica, libri e tutto quello che trovo
+		interessante questa settimana. Vivo in città con mia moglie e il nostro cane. Conduttore di un podcast
+		settimanale in cui parliamo con chi crea. Mamma, insegnante e orgogliosa di far parte della comunità.
+		Ama i viaggi, la fotografia e il buon cibo. Aiuto le piccole imprese a crescere online dal 2010. Studente
+		di storia, politica ed economia all'università. Tifoso di calcio, sempre pieno di speranza per la
+		prossima stagione. Ieri faceva bel tempo, così siamo andati al parco con i bambini e abbiamo mangiato
+		vicino al fiume. Ha detto che sarebbero tornati più tardi, ma nessuno sapeva quando sarebbe arrivato il
+		treno. Cosa ne pensi della nuova regola? Credo che sarebbe meglio se tutti avessero la possibilità di
+		parlare. Non c'è niente di più importante della salute, della famiglia e degli amici che ci sono quando
+		ne hai bisogno. Giornalista di scienza e ambiente. Autore di tre libri. Marito e papà. Cerco di imparare
+		qualcosa di nuovo ogni giorno e di condividerlo con voi. Infermiere, volontario e amante delle passeggiate.
+		Sviluppatore full stack e scienziato dei dati, dottorato in apprendimento automatico. Ingegnere backend,
+		responsabile di prodotto e designer. Direttore tecnico e cofondatore, investitore e consulente di giovani
+		imprese. Lavoro su cloud, sicurezza, infrastruttura e sviluppo mobile. Relatore, mentore e scrittore.
+		Ricercatrice in laboratorio, professore di informatica, analista, consulente e imprenditore. Responsabile
+		dell'ingegneria. Investo nei fondatori che costruiscono il futuro. Appassionato di dati, intelligenza
+		artificiale e scienza aperta. Avvocato, medico, artista, fotografo e giornalista libero professionista.
+		Stiamo assumendo sviluppatori, unisciti a noi. Esperta di marketing digitale e creatrice di contenuti. Ex
+		atleta, oggi allenatore della prossima generazione.`,
+	"pt": `Sou engenheiro de software e fundador de uma pequena empresa. Construímos ferramentas para as pessoas
+		que amam código aberto. Pai de dois filhos, apaixonado por café e corredor. As opiniões são minhas e não
+		do meu empregador. Escrevo sobre tecnologia, startups, design e o futuro do trabalho. Antes num banco,
+		agora trabalhando com a melhor equipe do mundo. Tuítes sobre música, livros e tudo o que acho
+		interessante nesta semana. Moro na cidade com a minha esposa e o nosso cachorro. Apresentador de um
+		podcast semanal onde conversamos com criadores. Mãe, professora e orgulhosa de fazer parte da comunidade.
+		Ela adora viagens, fotografia e boa comida. Ajudo pequenas empresas a crescer na internet desde 2010.
+		Estudante de história, política e economia na universidade. Torcedor de futebol, sempre com esperança na
+		próxima temporada. Ontem o tempo estava bom, então fomos ao parque com as crianças e almoçamos perto do
+		rio. Ele disse que voltariam mais tarde, mas ninguém sabia quando o trem ia chegar. O que você acha da
+		nova regra? Eu acho que seria melhor se todos tivessem a oportunidade de falar. Não há nada mais
+		importante do que a saúde, a família e os amigos que estão lá quando você precisa deles. Jornalista de
+		ciência e meio ambiente. Autor de três livros. Marido e pai. Tento aprender algo novo todos os dias e
+		compartilhar com vocês. Enfermeiro, voluntário e amante de longas caminhadas. Não desisto nunca, irmão.
+		Desenvolvedor full stack e cientista de dados, doutor em aprendizado de máquina. Engenheiro de backend,
+		gerente de produto e designer. Diretor de tecnologia e cofundador, investidor e conselheiro de empresas
+		iniciantes. Trabalho com nuvem, segurança, infraestrutura e desenvolvimento móvel. Palestrante, mentor e
+		escritor. Pesquisadora no laboratório, professor de computação, analista, consultor e empreendedor.
+		Responsável pela engenharia. Invisto em fundadores que constroem o futuro. Apaixonado por dados,
+		inteligência artificial e ciência aberta. Advogado, médica, artista, fotógrafo e jornalista autônomo.
+		Estamos contratando desenvolvedores, venha com a gente. Especialista em marketing digital e criadora de
+		conteúdo. Ex-atleta, agora treinador da próxima geração.`,
+	"nl": `Ik ben software-ontwikkelaar en oprichter van een klein bedrijf. Wij bouwen gereedschap voor mensen
+		die van open source houden. Vader van twee kinderen, koffieliefhebber en hardloper. De meningen zijn van
+		mijzelf en niet van mijn werkgever. Ik schrijf over technologie, startups, ontwerp en de toekomst van het
+		werk. Vroeger bij een bank, nu werkzaam met het beste team van de wereld. Tweets over muziek, boeken en
+		alles wat ik deze week interessant vind. Woon in de stad met mijn vrouw en onze hond. Presentator van een
+		wekelijkse podcast waarin we met makers praten. Moeder, lerares en trots lid van de gemeenschap. Zij houdt
+		van reizen, fotografie en lekker eten. Ik help kleine bedrijven sinds 2010 online te groeien. Student
+		geschiedenis, politiek en economie aan de universiteit. Voetbalfan, altijd vol hoop voor het volgende
+		seizoen. Gisteren was het mooi weer, dus zijn we met de kinderen naar het park gegaan en hebben we bij de
+		rivier gegeten. Hij zei dat ze later terug zouden komen, maar niemand wist wanneer de trein zou aankomen.
+		Wat vind jij van de nieuwe regel? Ik denk dat het beter zou zijn als iedereen de kans kreeg om te
+		spreken. Er is niets belangrijker dan gezondheid, familie en vrienden die er zijn als je ze nodig hebt.
+		Journalist voor wetenschap en milieu. Schrijver van drie boeken. Echtgenoot en papa. Ik probeer elke dag
+		iets nieuws te leren en dat met jullie te delen. Verpleegkundige, vrijwilliger en liefhebber van wandelen.
+		Full stack ontwikkelaar en datawetenschapper, gepromoveerd in machinaal leren. Ervaren backend
+		ingenieur, productmanager en ontwerper. Technisch directeur en medeoprichter, investeerder en adviseur van
+		jonge bedrijven. Ik werk aan de cloud, beveiliging, infrastructuur en mobiele ontwikkeling. Spreker, mentor
+		en schrijver. Onderzoeker in het lab, hoogleraar informatica, analist, adviseur en ondernemer. Hoofd van
+		de ontwikkeling. Ik investeer in oprichters die de toekomst bouwen. Gepassioneerd door data, kunstmatige
+		intelligentie en open wetenschap. Advocaat, arts, kunstenaar, fotograaf en freelance journalist. Wij zoeken
+		ontwikkelaars, kom ons team versterken. Specialist in digitale marketing. Voormalig sporter, nu trainer van
+		de volgende generatie.`,
+	"sv": `Jag är mjukvaruutvecklare och grundare av ett litet företag. Vi bygger verktyg för människor som
+		älskar öppen källkod. Pappa till två barn, kaffeälskare och löpare. Åsikterna är mina egna och inte min
+		arbetsgivares. Jag skriver om teknik, startups, design och framtidens arbete. Tidigare på en bank, nu med
+		världens bästa team. Tweets om musik, böcker och allt som jag tycker är intressant den här veckan. Bor i
+		staden med min fru och vår hund. Värd för en veckovis podcast där vi pratar med skapare. Mamma, lärare och
+		stolt medlem i samhället. Hon älskar att resa, fotografera och äta god mat. Jag hjälper små företag att
+		växa på nätet sedan 2010. Student i historia, politik och ekonomi vid universitetet. Fotbollsfantast,
+		alltid full av hopp inför nästa säsong. Igår var det fint väder, så vi gick till parken med barnen och
+		åt lunch vid ån. Han sa att de skulle komma tillbaka senare, men ingen visste när tåget skulle komma.
+		Vad tycker du om den nya regeln? Jag tror att det vore bättre om alla fick chansen att säga något. Det
+		finns inget viktigare än hälsa, familj och vänner som finns där när du behöver dem. Journalist inom
+		vetenskap och miljö. Författare till tre böcker. Make och pappa. Jag försöker lära mig något nytt varje
+		dag och dela det med er. Sjuksköterska, volontär och älskar långa promenader i skogen.
+		Fullstackutvecklare och datavetare med doktorsexamen i maskininlärning. Erfaren ingenjör, produktchef
+		och formgivare. Teknikchef och medgrundare, investerare och rådgivare till unga bolag. Jag arbetar med
+		molnet, säkerhet, infrastruktur och mobilutveckling. Talare, mentor och skribent. Forskare på labbet,
+		professor i datavetenskap, analytiker, konsult och entreprenör. Chef för utvecklingen. Jag investerar i
+		grundare som bygger framtiden. Brinner för data, artificiell intelligens och öppen vetenskap. Advokat,
+		läkare, konstnär, fotograf och frilansjournalist. Vi söker utvecklare, sök till oss. Specialist på digital
+		marknadsföring. Före detta idrottare, numera tränare för nästa generation.`,
+	"tr": `Yazılım mühendisiyim ve küçük bir şirketin kurucusuyum. Açık kaynağı seven insanlar için araçlar
+		geliştiriyoruz. İki çocuk babası, kahve tutkunu ve koşucu. Görüşler bana aittir, işverenimi bağlamaz.
+		Teknoloji, girişimler, tasarım ve işin geleceği hakkında yazıyorum. Önceden bir bankada çalıştım, şimdi
+		dünyanın en iyi ekibiyle çalışıyorum. Müzik, kitaplar ve bu hafta ilginç bulduğum her şey hakkında
+		tweetler. Eşim ve köpeğimizle şehirde yaşıyorum. Yapıcılarla konuştuğumuz haftalık bir podcastin sunucusu.
+		Anne, öğretmen ve topluluğun gururlu bir üyesi. Seyahat etmeyi, fotoğrafçılığı ve güzel yemekleri sever.
+		2010 yılından beri küçük işletmelerin internette büyümesine yardım ediyorum. Üniversitede tarih, siyaset
+		ve ekonomi öğrencisi. Futbol hayranı, her zaman gelecek sezon için umutlu. Dün hava güzeldi, bu yüzden
+		çocuklarla parka gittik ve nehrin kenarında yemek yedik. Daha sonra geri döneceklerini söyledi ama trenin
+		ne zaman geleceğini kimse bilmiyordu. Yeni kural hakkında ne düşünüyorsun? Bence herkesin konuşma şansı
+		olsaydı daha iyi olurdu. Sağlıktan, aileden ve ihtiyacın olduğunda yanında olan arkadaşlardan daha önemli
+		bir şey yoktur. Bilim ve çevre muhabiri. Üç kitabın yazarı. Eş ve baba. Her gün yeni bir şey öğrenmeye ve
+		bunu sizinle paylaşmaya çalışıyorum. Hemşire, gönüllü ve uzun yürüyüşlerin aşığı. Allah'ın izniyle.
+		Tam yığın geliştirici ve veri bilimci, makine öğrenmesi alanında doktora. Kıdemli arka uç mühendisi,
+		ürün müdürü ve tasarımcı. Teknoloji direktörü ve kurucu ortak, yatırımcı ve genç şirketlere danışman. Bulut,
+		güvenlik, altyapı ve mobil geliştirme üzerine çalışıyorum. Konuşmacı, mentor ve yazar. Laboratuvarda
+		araştırmacı, bilgisayar bilimleri profesörü, analist, danışman ve girişimci. Mühendislik yöneticisi.
+		Geleceği inşa eden kuruculara yatırım yapıyorum. Veri, yapay zeka ve açık bilim tutkunu. Avukat, doktor,
+		sanatçı, fotoğrafçı ve serbest gazeteci. Geliştirici arıyoruz, bize katılın. Dijital pazarlama uzmanı.
+		Eski sporcu, şimdi yeni neslin antrenörü.`,
+	"id": `Saya seorang insinyur perangkat lunak dan pendiri sebuah perusahaan kecil. Kami membangun alat untuk
+		orang yang mencintai sumber terbuka. Ayah dari dua anak, pecinta kopi dan pelari. Pendapat di sini adalah
+		milik saya sendiri dan bukan milik perusahaan tempat saya bekerja. Menulis tentang teknologi, perusahaan
+		rintisan, desain dan masa depan pekerjaan. Sebelumnya di sebuah bank, sekarang bekerja dengan tim terbaik
+		di dunia. Cuitan tentang musik, buku dan apa saja yang menarik minggu ini. Tinggal di kota bersama istri
+		dan anjing kami. Pembawa acara podcast mingguan yang berbicara dengan para pembuat. Ibu, guru dan anggota
+		komunitas yang bangga. Dia suka jalan-jalan, fotografi dan makanan enak. Membantu usaha kecil berkembang di
+		internet sejak 2010. Mahasiswa sejarah, politik dan ekonomi di universitas. Penggemar sepak bola, selalu
+		berharap untuk musim depan. Kemarin cuacanya cerah, jadi kami pergi ke taman dengan anak-anak dan makan
+		siang di tepi sungai. Dia bilang mereka akan kembali nanti, tetapi tidak ada yang tahu kapan keretanya akan
+		datang. Apa pendapatmu tentang aturan baru itu? Saya pikir akan lebih baik kalau semua orang punya
+		kesempatan untuk bicara. Tidak ada yang lebih penting daripada kesehatan, keluarga dan teman yang selalu
+		ada saat kamu membutuhkan mereka. Wartawan sains dan lingkungan. Penulis tiga buku. Suami dan bapak. Saya
+		berusaha belajar hal baru setiap hari dan membagikannya kepada kalian. Perawat, relawan dan suka berjalan.
+		Pengembang full stack dan ilmuwan data, doktor di bidang pembelajaran mesin. Insinyur senior, manajer
+		produk dan perancang. Direktur teknologi dan salah satu pendiri, investor dan penasihat perusahaan rintisan.
+		Saya bekerja di bidang komputasi awan, keamanan, infrastruktur dan pengembangan aplikasi. Pembicara, mentor
+		dan penulis. Peneliti di laboratorium, dosen ilmu komputer, analis, konsultan dan pengusaha. Kepala bagian
+		teknik. Berinvestasi pada pendiri yang membangun masa depan. Tertarik pada data, kecerdasan buatan dan ilmu
+		pengetahuan terbuka. Pengacara, dokter, seniman, fotografer dan wartawan lepas. Kami sedang mencari
+		pengembang, ayo bergabung. Spesialis pemasaran digital. Mantan atlet, sekarang pelatih generasi berikutnya.`,
+	"pl": `Jestem inżynierem oprogramowania i założycielem małej firmy. Budujemy narzędzia dla ludzi, którzy
+		kochają otwarte oprogramowanie. Ojciec dwójki dzieci, miłośnik kawy i biegacz. Opinie są moje własne, a
+		nie mojego pracodawcy. Piszę o technologii, startupach, projektowaniu i przyszłości pracy. Wcześniej w
+		banku, teraz pracuję z najlepszym zespołem na świecie. Tweety o muzyce, książkach i wszystkim, co uważam
+		za ciekawe w tym tygodniu. Mieszkam w mieście z żoną i naszym psem. Prowadzący cotygodniowy podcast, w
+		którym rozmawiamy z twórcami. Mama, nauczycielka i dumna członkini naszej społeczności. Kocha podróże,
+		fotografię i dobre jedzenie. Od 2010 roku pomagam małym firmom rozwijać się w internecie. Student historii,
+		polityki i ekonomii na uniwersytecie. Kibic piłki nożnej, zawsze z nadzieją na następny sezon. Wczoraj była
+		ładna pogoda, więc poszliśmy z dziećmi do parku i zjedliśmy obiad nad rzeką. Powiedział, że wrócą później,
+		ale nikt nie wiedział, kiedy przyjedzie pociąg. Co myślisz o nowej zasadzie? Myślę, że byłoby lepiej,
+		gdyby każdy miał szansę się wypowiedzieć. Nie ma nic ważniejszego niż zdrowie, rodzina i przyjaciele,
+		którzy są przy tobie, kiedy ich potrzebujesz. Dziennikarka naukowa i ekologiczna. Autor trzech książek.
+		Mąż i tata. Staram się codziennie nauczyć czegoś nowego i dzielić się tym z wami. Pielęgniarz i wolontariusz.
+		Programista full stack i analityk danych, doktor w dziedzinie uczenia maszynowego. Starszy inżynier,
+		menedżer produktu i projektantka. Dyrektor techniczny i współzałożyciel, inwestor i doradca młodych firm.
+		Pracuję nad chmurą, bezpieczeństwem, infrastrukturą i aplikacjami mobilnymi. Prelegent, mentor i pisarz.
+		Badaczka w laboratorium, profesor informatyki, analityk, konsultant i przedsiębiorca. Kierownik zespołu
+		inżynierów. Inwestuję w założycieli, którzy budują przyszłość. Pasjonat danych, sztucznej inteligencji i
+		otwartej nauki. Prawnik, lekarka, artysta, fotograf i niezależny dziennikarz. Szukamy programistów, dołącz
+		do nas. Specjalistka od marketingu cyfrowego. Były sportowiec, teraz trener następnego pokolenia.`,
+}
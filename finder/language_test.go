@@ -0,0 +1,198 @@
+package finder
+
+import (
+	"testing"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// languageBios : profile bios that are not in languageCorpus
+var languageBios = map[string][]string{
+	"en": {
+		"Product designer at a fintech startup. I love hiking, cooking and terrible puns.",
+		"Dad of three, runner and amateur baker. Tweets are my own.",
+		"PhD student working on climate models. She/her. Coffee first.",
+		"Writing code and breaking things since the nineties",
+		"Just a guy who likes old movies and good books",
+	},
+	"de": {
+		"Softwarearchitekt aus Leidenschaft. Ich mag Berge, Bier und gute Bücher.",
+		"Mama von zwei Jungs, arbeite im Marketing und backe gerne Kuchen.",
+		"Hier twittere ich über Fußball, Politik und das Leben auf dem Land.",
+		"Doktorand an der Uni, forsche zu erneuerbaren Energien",
+		"Immer auf der Suche nach dem nächsten Abenteuer",
+	},
+	"fr": {
+		"Développeuse web passionnée, je partage mes astuces et mes coups de cœur.",
+		"Papa de trois enfants, cycliste du dimanche et amoureux de la Bretagne.",
+		"Journaliste indépendant. Je parle de politique, de sport et de cinéma.",
+		"Étudiante en médecine, fan de séries et de chats",
+		"Toujours à la recherche d'un bon livre et d'un café",
+	},
+	"es": {
+		"Diseñadora gráfica y amante de los gatos. Vivo en Madrid desde hace diez años.",
+		"Profesor de matemáticas, padre de familia y hincha del Betis.",
+		"Escribo sobre economía, cine y los pequeños placeres de la vida.",
+		"Desarrollador backend, me gusta el buen vino y la montaña",
+		"Mis tuits no representan a mi empresa",
+	},
+	"it": {
+		"Sviluppatrice web appassionata di montagna, cucina e gatti.",
+		"Papà di due bambine, lavoro nel marketing e tifo per la Roma.",
+		"Scrivo di politica, cinema e della vita nella mia città.",
+		"Studente di ingegneria, sempre con un libro in mano",
+		"Le mie opinioni sono soltanto mie",
+	},
+	"pt": {
+		"Desenvolvedora web apaixonada por gatos, cinema e viagens.",
+		"Pai de duas meninas, professor de matemática e torcedor do Flamengo.",
+		"Escrevo sobre economia, futebol e as coisas boas da vida.",
+		"Estudante de medicina, não vivo sem café e música",
+		"Minhas opiniões não representam a empresa onde trabalho",
+	},
+	"nl": {
+		"Webontwikkelaar met een passie voor fietsen, koken en goede koffie.",
+		"Moeder van drie, werk in de zorg en lees graag thrillers.",
+		"Ik schrijf over politiek, voetbal en het leven in Amsterdam.",
+		"Student informatica aan de universiteit van Utrecht",
+		"Altijd op zoek naar het volgende avontuur",
+	},
+	"sv": {
+		"Webbutvecklare som älskar skidåkning, matlagning och bra kaffe.",
+		"Mamma till tre, jobbar inom vården och läser gärna deckare.",
+		"Jag skriver om politik, fotboll och livet i Göteborg.",
+		"Doktorand vid universitetet, forskar om förnybar energi",
+		"Alltid på jakt efter nästa äventyr",
+	},
+	"tr": {
+		"Web geliştirici, kedileri, kahveyi ve iyi kitapları seviyorum.",
+		"İki çocuk annesi, öğretmen ve kitap kurdu.",
+		"Siyaset, futbol ve İstanbul'daki hayat hakkında yazıyorum.",
+		"Bilgisayar mühendisliği öğrencisi, müzik tutkunu",
+		"Burada yazdıklarım sadece benim görüşlerimdir",
+	},
+	"id": {
+		"Pengembang web yang suka kucing, kopi dan buku bagus.",
+		"Ibu dari tiga anak, bekerja di rumah sakit dan suka membaca.",
+		"Saya menulis tentang politik, sepak bola dan kehidupan di Jakarta.",
+		"Mahasiswa teknik informatika, pecinta musik",
+		"Semua cuitan di sini adalah pendapat pribadi",
+	},
+	"pl": {
+		"Programistka, która kocha koty, kawę i dobre książki.",
+		"Tata trójki dzieci, pracuję w szpitalu i lubię czytać kryminały.",
+		"Piszę o polityce, piłce nożnej i życiu w Krakowie.",
+		"Student informatyki na politechnice, fan muzyki",
+		"Wszystkie opinie są wyłącznie moje",
+	},
+}
+
+// minBioConfidence : the confidence of every bio in languageBios at least
+const minBioConfidence = 0.5
+
+func TestDetectLanguageAccuracy(t *testing.T) {
+	for code, bios := range languageBios {
+		correct := 0
+		for _, bio := range bios {
+			l := detectLanguage(bio)
+			if l.Code != code {
+				t.Errorf("%v: %q detected as %v (%v)", code, bio, l.Code, l.Confidence)
+				continue
+			}
+			correct++
+			if l.Confidence < minBioConfidence {
+				t.Errorf("%v: %q confidence %v, expected %v at least", code, bio, l.Confidence, minBioConfidence)
+			}
+		}
+		if accuracy := float64(correct) / float64(len(bios)); accuracy < 1 {
+			t.Errorf("%v: accuracy %v", code, accuracy)
+		}
+	}
+}
+
+func TestDetectLanguageScripts(t *testing.T) {
+	tests := []struct {
+		text string
+		code string
+	}{
+		{"مهندس برمجيات ومحب للقهوة والكتب", "ar"},
+		{"برنامه نویس و عاشق کتاب و چای", "fa"},
+		{"Программист, люблю кофе и хорошие книги", "ru"},
+		{"Програміст, люблю каву і гарні книжки", "uk"},
+		{"Προγραμματιστής και λάτρης του καφέ", "el"},
+		{"ソフトウェアエンジニア、コーヒーが好きです", "ja"},
+		{"软件工程师，喜欢咖啡和读书的人", "zh"},
+		{"소프트웨어 엔지니어이고 커피를 좋아합니다", "ko"},
+		// too short or without letters
+		{"dev", ""},
+		{"https://go.dev @golang #go 2024", ""},
+	}
+	for _, tt := range tests {
+		l := detectLanguage(tt.text)
+		if l.Code != tt.code {
+			t.Errorf("%q detected as %v (%v), expected %v", tt.text, l.Code, l.Confidence, tt.code)
+		}
+		if tt.code != "" && l.Confidence < minBioConfidence {
+			t.Errorf("%q confidence %v, expected %v at least", tt.text, l.Confidence, minBioConfidence)
+		}
+	}
+}
+
+func TestDetectLanguageTechnicalBios(t *testing.T) {
+	tests := []struct {
+		text string
+		code string
+	}{
+		{"Full stack developer", "en"},
+		{"golang rust k8s", "en"},
+		{"Data scientist, PhD", "en"},
+		{"CTO @acme | ex-Google | angel investor", "en"},
+		// as close to two languages
+		{"Foto Video Radio", ""},
+		{"Data analista", ""},
+	}
+	for _, tt := range tests {
+		l := detectLanguage(tt.text)
+		if l.Code != tt.code {
+			t.Errorf("%q detected as %v (%v), expected %v", tt.text, l.Code, l.Confidence, tt.code)
+		}
+		if tt.code != "" && l.Confidence < minBioConfidence {
+			t.Errorf("%q confidence %v, expected %v at least", tt.text, l.Confidence, minBioConfidence)
+		}
+		if tt.code == "" && l.Confidence != 0 {
+			t.Errorf("%q unknown with confidence %v", tt.text, l.Confidence)
+		}
+	}
+}
+
+func TestLanguageCriteria(t *testing.T) {
+	recordErrors(t)
+	f, err := NewFinder(config.SearchCriteria{Languages: []string{"pt"}, LanguageConfidence: 0.3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for code, bios := range languageBios {
+		for _, bio := range bios {
+			u := anaconda.User{Name: "Ana", Description: bio}
+			if report := f.Match(&u); report.Matched != (code == "pt") {
+				t.Errorf("%v bio %q matched %v: %v", code, bio, report.Matched, report)
+			}
+		}
+	}
+
+	// the default confidence does not let the unknown texts match
+	f, err = NewFinder(config.SearchCriteria{Languages: []string{"it", "en"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.languageConfidence != defaultLanguageConfidence {
+		t.Errorf("language confidence %v, expected %v", f.languageConfidence, defaultLanguageConfidence)
+	}
+	for bio, matched := range map[string]bool{"Full stack developer": true, "Foto Video Radio": false, "Ana": false} {
+		u := anaconda.User{Description: bio}
+		if report := f.Match(&u); report.Matched != matched {
+			t.Errorf("bio %q matched %v, expected %v: %v", bio, report.Matched, matched, report)
+		}
+	}
+}
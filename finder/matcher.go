@@ -0,0 +1,219 @@
+package finder
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// matchMode : how the keyword is matched against the user text
+type matchMode int
+
+const (
+	substringMode matchMode = iota
+	wordMode
+	phraseMode
+	prefixMode
+	regexMode
+	fuzzyMode
+)
+
+// matchModes : keyword mode prefixes e.g. "word:CTO", "regex:^go(lang)?$"
+var matchModes = map[string]matchMode{
+	"substring": substringMode,
+	"word":      wordMode,
+	"phrase":    phraseMode,
+	"prefix":    prefixMode,
+	"regex":     regexMode,
+	"fuzzy":     fuzzyMode,
+}
+
+func (m matchMode) String() string {
+	for k, v := range matchModes {
+		if v == m {
+			return k
+		}
+	}
+	return "unknown"
+}
+
+// matcher : compiled keyword
+type matcher struct {
+	mode    matchMode
+	keyword string
+	re      *regexp.Regexp
+	// fuzzy : threshold and skeleton of the fuzzy keyword
+	fuzzy *fuzzyKeyword
+	// weight : keyword weight in scoring mode, e.g. golang^2
+	weight float64
+	// fold : prepare the keyword and the text before matching
+	fold func(string) string
+}
+
+// parseKeyword : build matcher from keyword syntax
+//   - keyword            substring (default)
+//   - "exact phrase"     phrase
+//   - mode:keyword       one of substring, word, phrase, prefix, regex or fuzzy
+//   - fuzzy:keyword~2    fuzzy with edit distance, or similarity e.g. fuzzy:Mohamed~0.75
+//   - \keyword           the rest is a literal substring, e.g. \-net
+//   - keyword^weight     keyword weight in scoring mode, e.g. golang^2, except regex where ^ is part of the pattern
+func parseKeyword(keyword string, fold func(string) string) (*matcher, error) {
+	if mode, _, ok := splitMatchMode(keyword); ok && mode == regexMode {
+		return parseKeywordMode(keyword, fold)
+	}
+	keyword, weight := splitWeight(keyword)
+	m, err := parseKeywordMode(keyword, fold)
+	if err != nil {
+		return nil, err
+	}
+	if weight != 0 {
+		m.weight = weight
+	}
+	return m, nil
+}
+
+func parseKeywordMode(keyword string, fold func(string) string) (*matcher, error) {
+	if strings.HasPrefix(keyword, `\`) {
+		return newMatcher(substringMode, keyword[1:], fold)
+	}
+	if len(keyword) > 1 && strings.HasPrefix(keyword, `"`) && strings.HasSuffix(keyword, `"`) {
+		return newMatcher(phraseMode, keyword[1:len(keyword)-1], fold)
+	}
+	if mode, rest, ok := splitMatchMode(keyword); ok {
+		return newMatcher(mode, rest, fold)
+	}
+	return newMatcher(substringMode, keyword, fold)
+}
+
+// splitWeight : split "keyword^weight", zero weight if not exist
+func splitWeight(keyword string) (string, float64) {
+	idx := strings.LastIndexByte(keyword, '^')
+	if idx <= 0 {
+		return keyword, 0
+	}
+	weight, err := strconv.ParseFloat(keyword[idx+1:], 64)
+	if err != nil {
+		return keyword, 0
+	}
+	return keyword[:idx], weight
+}
+
+// splitMatchMode : split "mode:keyword" if mode is known
+func splitMatchMode(keyword string) (matchMode, string, bool) {
+	idx := strings.IndexByte(keyword, ':')
+	if idx <= 0 {
+		return substringMode, keyword, false
+	}
+	mode, ok := matchModes[strings.ToLower(keyword[:idx])]
+	return mode, keyword[idx+1:], ok
+}
+
+// newMatcher : compile the keyword with the match mode
+// fold is applied to the keyword and the text, e.g. normalize or strings.ToLower
+func newMatcher(mode matchMode, keyword string, fold func(string) string) (*matcher, error) {
+	m := &matcher{mode: mode, keyword: keyword, fold: fold, weight: 1}
+	switch mode {
+	case regexMode:
+		re, err := regexp.Compile(keyword)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %v", keyword, err)
+		}
+		m.re = re
+		return m, nil
+	case phraseMode:
+		m.keyword = strings.Join(strings.Fields(keyword), " ")
+	case fuzzyMode:
+		keyword, threshold := splitFuzzyThreshold(keyword)
+		m.keyword = strings.Join(fuzzyWords(fold(keyword)), " ")
+		if m.keyword == "" {
+			return nil, fmt.Errorf("empty %v keyword", mode)
+		}
+		m.fuzzy = newFuzzyKeyword(m.keyword)
+		if err := m.fuzzy.setThreshold(threshold); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	m.keyword = fold(m.keyword)
+	if m.keyword == "" {
+		return nil, fmt.Errorf("empty %v keyword", mode)
+	}
+	return m, nil
+}
+
+// match : check if the text match the keyword
+// regex is matched against the original text, all the other modes use the folded text
+func (m *matcher) match(text string) bool {
+	matched, _ := m.explain(text)
+	return matched
+}
+
+// explain : match the text, and how close the fuzzy hit was
+func (m *matcher) explain(text string) (bool, string) {
+	if m.mode == fuzzyMode {
+		hit, ok := m.fuzzy.closest(m.keyword, m.fold(splitHandle(text)))
+		if hit.text == "" {
+			return ok, ""
+		}
+		return ok, hit.String()
+	}
+	return m.matchText(text), ""
+}
+
+func (m *matcher) matchText(text string) bool {
+	if m.mode == regexMode {
+		return m.re.MatchString(text)
+	}
+	text = m.fold(text)
+	switch m.mode {
+	case wordMode:
+		return boundedIndex(text, m.keyword, true) >= 0
+	case phraseMode:
+		return boundedIndex(strings.Join(strings.Fields(text), " "), m.keyword, true) >= 0
+	case prefixMode:
+		return boundedIndex(text, m.keyword, false) >= 0
+	}
+	return strings.Contains(text, m.keyword)
+}
+
+func (m *matcher) String() string {
+	res := strconv.Quote(m.keyword)
+	if m.mode != substringMode {
+		res = fmt.Sprintf("%v:%v", m.mode, res)
+	}
+	if m.mode == fuzzyMode {
+		res = fmt.Sprintf("%v~%v", res, m.fuzzy.threshold())
+	}
+	if m.weight != 1 {
+		res = fmt.Sprintf("%v^%v", res, m.weight)
+	}
+	return res
+}
+
+// boundedIndex : index of keyword in text that starts at a word boundary,
+// and if wholeWord ends at a word boundary too.
+func boundedIndex(text, keyword string, wholeWord bool) int {
+	for offset := 0; offset <= len(text); {
+		idx := strings.Index(text[offset:], keyword)
+		if idx < 0 {
+			return -1
+		}
+		start := offset + idx
+		end := start + len(keyword)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (!wholeWord || end == len(text) || !isWordRune(after)) {
+			return start
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
@@ -0,0 +1,116 @@
+package finder
+
+import (
+	"strings"
+	"testing"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+func TestParseKeyword(t *testing.T) {
+	tests := []struct {
+		keyword string
+		mode    matchMode
+		weight  float64
+		text    string
+		matched bool
+	}{
+		// substring
+		{"go", substringMode, 1, "Golang developer", true},
+		{"go^2", substringMode, 2, "Golang developer", true},
+		{"go^x", substringMode, 1, "go^x fan", true},
+		// word
+		{"word:go", wordMode, 1, "I write Go daily", true},
+		{"word:go", wordMode, 1, "Golang developer", false},
+		{"word:CTO^3", wordMode, 3, "CTO @acme", true},
+		{"word:c++", wordMode, 1, "C++ and Go", true},
+		// phrase
+		{`"open source"`, phraseMode, 1, "Open   Source maintainer", true},
+		{`"open source"^2`, phraseMode, 2, "opensource maintainer", false},
+		{"phrase:open source", phraseMode, 1, "open sourcerer", false},
+		// prefix
+		{"prefix:dev", prefixMode, 1, "DevOps engineer", true},
+		{"prefix:dev^1.5", prefixMode, 1.5, "webdev", false},
+		{"prefix:ops", prefixMode, 1, "DevOps engineer", false},
+		// regex, ^ is part of the pattern
+		{"regex:x^2", regexMode, 1, "x^2", false},
+		{`regex:x\^2`, regexMode, 1, "f(x) = x^2", true},
+		{"regex:^go(lang)?$", regexMode, 1, "golang", true},
+		{"regex:^go(lang)?$", regexMode, 1, "golang developer", false},
+		{"regex:(?i)^[a-z]+ ?dev$", regexMode, 1, "Web Dev", true},
+		// \ escapes the rest as a literal substring
+		{`\-net`, substringMode, 1, "dot-net developer", true},
+		{`\-net`, substringMode, 1, "dotnet developer", false},
+		{`\word:go`, substringMode, 1, "word:go", true},
+	}
+	for _, tt := range tests {
+		m, err := parseKeyword(tt.keyword, normalize)
+		if err != nil {
+			t.Errorf("%v: %v", tt.keyword, err)
+			continue
+		}
+		if m.mode != tt.mode || m.weight != tt.weight {
+			t.Errorf("%v: %v mode %v weight %v, expected %v weight %v", tt.keyword, m, m.mode, m.weight, tt.mode, tt.weight)
+		}
+		if matched := m.match(tt.text); matched != tt.matched {
+			t.Errorf("%v: %q matched %v, expected %v", tt.keyword, tt.text, matched, tt.matched)
+		}
+	}
+}
+
+func TestParseKeywordErrors(t *testing.T) {
+	tests := []struct {
+		keyword string
+		err     string
+	}{
+		{"regex:go(lang", `invalid regular expression "go(lang"`},
+		{"regex:[a-", `invalid regular expression "[a-"`},
+		{"regex:*go", `invalid regular expression "*go"`},
+		{"word:", "empty word keyword"},
+		{"prefix:^2", "empty prefix keyword"},
+		{`""`, "empty phrase keyword"},
+	}
+	for _, tt := range tests {
+		m, err := parseKeyword(tt.keyword, normalize)
+		if err == nil {
+			t.Errorf("%v: parsed as %v, expected error %q", tt.keyword, m, tt.err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%v: error %q, expected %q", tt.keyword, err, tt.err)
+		}
+	}
+
+	_, err := NewFinder(config.SearchCriteria{SearchBioContext: []string{"golang", "regex:go(lang"}})
+	if err == nil || !strings.Contains(err.Error(), "bio context") {
+		t.Errorf("invalid regex in the bio context: %v", err)
+	}
+}
+
+func TestContextKeywordEscape(t *testing.T) {
+	recordErrors(t)
+	tests := []struct {
+		keywords []string
+		bio      string
+		matched  bool
+	}{
+		{[]string{"developer", "-net"}, "dot-net developer", false},
+		{[]string{"developer", "-net"}, "go developer", true},
+		{[]string{`\-net`}, "dot-net developer", true},
+		{[]string{`\-net`}, "dotnet developer", false},
+		{[]string{"developer", `-\-net`}, "dot-net developer", false},
+		{[]string{"developer", `-\-net`}, "dotnet developer", true},
+	}
+	for _, tt := range tests {
+		f, err := NewFinder(config.SearchCriteria{SearchBioContext: tt.keywords})
+		if err != nil {
+			t.Errorf("%v: %v", tt.keywords, err)
+			continue
+		}
+		u := anaconda.User{Description: tt.bio}
+		if report := f.Match(&u); report.Matched != tt.matched {
+			t.Errorf("%v: %q matched %v, expected %v: %v", tt.keywords, tt.bio, report.Matched, tt.matched, report)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package finder
+
+import (
+	"time"
+)
+
+// derived metrics computed from the user profile,
+// zero denominators are counted as one to avoid division by zero.
+
+// userAccountAge : account age in days
+func userAccountAge(u *profile) int64 {
+	joined := userJoined(u)
+	if joined.IsZero() {
+		return 0
+	}
+	return daysSince(joined)
+}
+
+// daysSince : full days since t, 0 if t is in the future e.g. clock skew
+func daysSince(t time.Time) int64 {
+	if d := time.Since(t); d > 0 {
+		return int64(d.Hours() / 24)
+	}
+	return 0
+}
+
+// userFollowersRatio : followers / following
+func userFollowersRatio(u *profile) float64 {
+	return ratio(float64(u.FollowersCount), float64(u.FriendsCount))
+}
+
+// userTweetsPerDay : average tweets per day since joining
+func userTweetsPerDay(u *profile) float64 {
+	return ratio(float64(u.StatusesCount), float64(userAccountAge(u)))
+}
+
+// userLikesPerTweet : likes / tweets
+func userLikesPerTweet(u *profile) float64 {
+	return ratio(float64(u.FavouritesCount), float64(u.StatusesCount))
+}
+
+// userListedPer1KFollowers : lists the user is member of for every 1000 followers
+func userListedPer1KFollowers(u *profile) float64 {
+	return ratio(float64(u.ListedCount)*1000, float64(u.FollowersCount))
+}
+
+func ratio(a, b float64) float64 {
+	if b < 1 {
+		b = 1
+	}
+	return a / b
+}
@@ -0,0 +1,109 @@
+package finder
+
+import (
+	"testing"
+	"time"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+func TestDaysSince(t *testing.T) {
+	tests := []struct {
+		since time.Duration
+		days  int64
+	}{
+		{0, 0},
+		{23 * time.Hour, 0},
+		{36 * time.Hour, 1},
+		{400 * 24 * time.Hour, 400},
+		// in the future e.g. clock skew
+		{-48 * time.Hour, 0},
+	}
+	for _, tt := range tests {
+		if d := daysSince(time.Now().Add(-tt.since)); d != tt.days {
+			t.Errorf("%v ago: %v days, expected %v", tt.since, d, tt.days)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	rec := recordErrors(t)
+	joined := time.Now().AddDate(0, 0, -100).Format(time.RubyDate)
+	tests := []struct {
+		name         string
+		user         anaconda.User
+		age          int64
+		ratio        float64
+		tweetsPerDay float64
+		likes        float64
+		listed       float64
+	}{
+		{"zero counts", anaconda.User{CreatedAt: joined}, 100, 0, 0, 0, 0},
+		{"no following", anaconda.User{CreatedAt: joined, FollowersCount: 50}, 100, 50, 0, 0, 0},
+		{"no tweets", anaconda.User{CreatedAt: joined, FavouritesCount: 30}, 100, 0, 0, 30, 0},
+		{"no followers", anaconda.User{CreatedAt: joined, ListedCount: 2}, 100, 0, 0, 0, 2000},
+		{"counts", anaconda.User{CreatedAt: joined, FollowersCount: 2000, FriendsCount: 500, StatusesCount: 250, FavouritesCount: 500, ListedCount: 10}, 100, 4, 2.5, 2, 5},
+		// the age is 0 and the tweets are counted as in one day
+		{"missing created_at", anaconda.User{StatusesCount: 7}, 0, 0, 7, 0, 0},
+		{"invalid created_at", anaconda.User{CreatedAt: "2007-05-23", StatusesCount: 7}, 0, 0, 7, 0, 0},
+		{"joined today", anaconda.User{CreatedAt: time.Now().Format(time.RubyDate), StatusesCount: 3}, 0, 0, 3, 0, 0},
+	}
+	for _, tt := range tests {
+		u := newProfile(&tt.user, nil)
+		if age := userAccountAge(u); age != tt.age {
+			t.Errorf("%v: age %v, expected %v", tt.name, age, tt.age)
+		}
+		if r := userFollowersRatio(u); r != tt.ratio {
+			t.Errorf("%v: ratio %v, expected %v", tt.name, r, tt.ratio)
+		}
+		if r := userTweetsPerDay(u); r != tt.tweetsPerDay {
+			t.Errorf("%v: tweets per day %v, expected %v", tt.name, r, tt.tweetsPerDay)
+		}
+		if r := userLikesPerTweet(u); r != tt.likes {
+			t.Errorf("%v: likes per tweet %v, expected %v", tt.name, r, tt.likes)
+		}
+		if r := userListedPer1KFollowers(u); r != tt.listed {
+			t.Errorf("%v: listed per 1k followers %v, expected %v", tt.name, r, tt.listed)
+		}
+	}
+	// the invalid created_at is logged, the missing one is not a date
+	if rec.errors == 0 {
+		t.Error("invalid created_at not logged")
+	}
+}
+
+func TestInactiveDays(t *testing.T) {
+	u := anaconda.User{Status: &anaconda.Tweet{CreatedAt: time.Now().AddDate(0, 0, -10).Add(-time.Hour).Format(time.RubyDate)}}
+	if d := userInactiveDays(newProfile(&u, nil)); d != 10 {
+		t.Errorf("inactive days %v, expected 10", d)
+	}
+}
+
+func TestMetricsCriteria(t *testing.T) {
+	recordErrors(t)
+	tests := []struct {
+		query   string
+		user    anaconda.User
+		matched bool
+	}{
+		{"ratio:..1", anaconda.User{}, true},
+		{"ratio:1..", anaconda.User{}, false},
+		{"age:..1", anaconda.User{}, true},
+		{"age:365..", anaconda.User{}, false},
+		{"tweets_per_day:5..", anaconda.User{StatusesCount: 7}, true},
+		{"likes_per_tweet:..0.5", anaconda.User{FavouritesCount: 1, StatusesCount: 4}, true},
+		{"listed_per_1k:1..", anaconda.User{ListedCount: 1, FollowersCount: 5000}, false},
+	}
+	for _, tt := range tests {
+		f, err := NewFinder(config.SearchCriteria{Query: tt.query})
+		if err != nil {
+			t.Errorf("%v: %v", tt.query, err)
+			continue
+		}
+		u := tt.user
+		if report := f.Match(&u); report.Matched != tt.matched {
+			t.Errorf("%v: matched %v, expected %v: %v", tt.query, report.Matched, tt.matched, report)
+		}
+	}
+}
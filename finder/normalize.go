@@ -0,0 +1,86 @@
+package finder
+
+import (
+	"strings"
+	"unicode"
+)
+
+const tatweel = 'ـ'
+
+// foldTable : lower case letters folded to their base form
+var foldTable = buildFoldTable()
+
+func buildFoldTable() map[rune]string {
+	table := map[rune]string{}
+	groups := map[string]string{
+		// latin
+		"a":  "àáâãäåāăąǎ",
+		"ae": "æ",
+		"c":  "çćĉċč",
+		"d":  "ďđð",
+		"e":  "èéêëēĕėęě",
+		"g":  "ĝğġģ",
+		"h":  "ĥħ",
+		"i":  "ìíîïĩīĭįıǐ",
+		"ij": "ĳ",
+		"j":  "ĵ",
+		"k":  "ķ",
+		"l":  "ĺļľŀł",
+		"n":  "ñńņňŉ",
+		"o":  "òóôõöøōŏőơǒ",
+		"oe": "œ",
+		"r":  "ŕŗř",
+		"s":  "śŝşšș",
+		"ss": "ß",
+		"t":  "ţťŧț",
+		"th": "þ",
+		"u":  "ùúûüũūŭůűųưǔ",
+		"w":  "ŵ",
+		"y":  "ýÿŷ",
+		"z":  "źżž",
+		// greek
+		"σ": "ς",
+		// arabic letter variants
+		"ا": "أإآٱ",
+		"ه": "ة",
+		"ي": "ىیئ",
+		"ك": "ک",
+		"و": "ؤ",
+		// arabic-indic and persian digits
+		"0": "٠۰", "1": "١۱", "2": "٢۲", "3": "٣۳", "4": "٤۴",
+		"5": "٥۵", "6": "٦۶", "7": "٧۷", "8": "٨۸", "9": "٩۹",
+	}
+	for base, variants := range groups {
+		for _, r := range variants {
+			table[r] = base
+		}
+	}
+	return table
+}
+
+// normalize : prepare text for keyword matching
+// - case folding, including the turkish dotted/dotless i
+// - fullwidth characters to their ascii form
+// - strip diacritics, arabic harakat and tatweel
+// - fold script specific letter variants e.g. (أ/ا/إ)
+func normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r), r == tatweel:
+			continue
+		case r >= '！' && r <= '～':
+			r -= 0xFEE0
+		case r == '　':
+			r = ' '
+		}
+		r = unicode.ToLower(r)
+		if f, ok := foldTable[r]; ok {
+			b.WriteString(f)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
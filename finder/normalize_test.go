@@ -0,0 +1,85 @@
+package finder
+
+import (
+	"testing"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		// README examples
+		{"İstanbul", "istanbul"},
+		{"Müller", "muller"},
+		{"أحمد", "احمد"},
+		// case folding, turkish dotless i
+		{"GOLANG", "golang"},
+		{"ISPARTA ılık", "isparta ilik"},
+		// diacritics, precomposed and combining
+		{"Café Zürich", "cafe zurich"},
+		{"Müller", "muller"},
+		{"Straße Øresund", "strasse oresund"},
+		{"Łódź", "lodz"},
+		// fullwidth characters and the ideographic space
+		{"Ｇｏｌａｎｇ　２０２４！", "golang 2024!"},
+		// arabic harakat, tatweel and letter variants
+		{"مُحَمَّد", "محمد"},
+		{"مـــحـــمد", "محمد"},
+		{"إسلام آمنة", "اسلام امنه"},
+		{"مصطفى", "مصطفي"},
+		{"كتاب کتاب", "كتاب كتاب"},
+		// arabic-indic and persian digits
+		{"٢٠٢٤ ۲۰۲۴", "2024 2024"},
+		// greek final sigma
+		{"ΟΔΥΣΣΕΥΣ", "οδυσσευσ"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalize(tt.in); got != tt.expected {
+			t.Errorf("normalize(%q) = %q, expected %q", tt.in, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalizationMatch(t *testing.T) {
+	recordErrors(t)
+	tests := []struct {
+		keyword string
+		bio     string
+		// matched, matchedDisabled : with and without the normalization
+		matched         bool
+		matchedDisabled bool
+	}{
+		// İ is lower cased to i without the normalization too
+		{"istanbul", "Living in İstanbul", true, true},
+		{"muller", "Thomas Müller fan", true, false},
+		{"احمد", "أحمد من القاهرة", true, false},
+		{"İstanbul", "istanbul", true, true},
+		{"istanbul", "ıstanbul", true, false},
+		{"Müller", "MÜLLER", true, true},
+		{"golang", "GOLANG developer", true, true},
+		{"golang", "Ｇｏｌａｎｇ developer", true, false},
+		{"2024", "since ٢٠٢٤", true, false},
+		{"rust", "Go developer", false, false},
+	}
+	for _, tt := range tests {
+		for _, disabled := range []bool{false, true} {
+			f, err := NewFinder(config.SearchCriteria{SearchBioContext: []string{tt.keyword}, DisableNormalization: disabled})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := tt.matched
+			if disabled {
+				expected = tt.matchedDisabled
+			}
+			u := anaconda.User{Description: tt.bio}
+			if report := f.Match(&u); report.Matched != expected {
+				t.Errorf("%q in %q (DISABLE_NORMALIZATION %v): matched %v, expected %v", tt.keyword, tt.bio, disabled, report.Matched, expected)
+			}
+		}
+	}
+}
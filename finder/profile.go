@@ -0,0 +1,122 @@
+package finder
+
+import (
+	"encoding/json"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// Profile : the looked up user with the t.co links of the bio, anaconda.User does not decode entities.description
+type Profile struct {
+	anaconda.User
+	// BioURLs : the links of the bio and the urls they expand to
+	BioURLs []BioURL `json:"BIO_URLS,omitempty"`
+}
+
+// BioURL : t.co link of the bio and its expanded url
+type BioURL struct {
+	URL         string `json:"URL"`
+	ExpandedURL string `json:"EXPANDED_URL"`
+}
+
+// rawProfile : the user fields that anaconda.User does not decode
+type rawProfile struct {
+	Entities struct {
+		Description struct {
+			Urls []struct {
+				Url          string `json:"url"`
+				Expanded_url string `json:"expanded_url"`
+			} `json:"urls"`
+		} `json:"description"`
+	} `json:"entities"`
+}
+
+// DecodeProfile : decode the user JSON of the twitter API or the stored results,
+// the links of the bio are read from entities.description or from BIO_URLS of the stored results.
+func DecodeProfile(data []byte) (Profile, error) {
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, err
+	}
+	var raw rawProfile
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return p, err
+	}
+	if len(p.BioURLs) == 0 {
+		for _, u := range raw.Entities.Description.Urls {
+			p.BioURLs = append(p.BioURLs, BioURL{URL: u.Url, ExpandedURL: u.Expanded_url})
+		}
+	}
+	return p, nil
+}
+
+// DecodeProfiles : decode the users JSON array e.g. the users/lookup response
+func DecodeProfiles(data []byte) ([]Profile, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, err
+	}
+	res := make([]Profile, 0, len(raws))
+	for _, raw := range raws {
+		p, err := DecodeProfile(raw)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// profile : the user being matched and the values derived from it,
+// the language, place and bot score are computed on first use by one Match and kept in its report.
+type profile struct {
+	*anaconda.User
+	bioURLs  []BioURL
+	language *Language
+	place    *Place
+	bot      *BotScore
+}
+
+func newProfile(u *anaconda.User, bioURLs []BioURL) *profile {
+	return &profile{User: u, bioURLs: bioURLs}
+}
+
+// languageOf : detect the language once
+func (p *profile) languageOf() Language {
+	if p.language == nil {
+		l := detectLanguage(userLanguageText(p.User))
+		p.language = &l
+	}
+	return *p.language
+}
+
+// placeOf : resolve the location once
+func (p *profile) placeOf() Place {
+	if p.place == nil {
+		pl := resolvePlace(p.Location)
+		p.place = &pl
+	}
+	return *p.place
+}
+
+// botOf : score the bot signals once
+func (p *profile) botOf() BotScore {
+	if p.bot == nil {
+		b := botScore(p.User)
+		p.bot = &b
+	}
+	return *p.bot
+}
+
+// computed : copy the values computed so far to the report
+func (p *profile) computed(r *MatchReport) {
+	if p.language != nil {
+		r.Language = *p.language
+	}
+	if p.place != nil {
+		r.Place = *p.place
+	}
+	if p.bot != nil {
+		r.Bot = *p.bot
+	}
+}
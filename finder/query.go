@@ -0,0 +1,437 @@
+package finder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"twfinder/config"
+	"unicode"
+)
+
+// query language
+//
+//	query   := or
+//	or      := and { "OR" and }
+//	and     := unary { ["AND"] unary }
+//	unary   := "NOT" unary | "-" unary | primary
+//	primary := "(" or ")" | field ":" unary | word | "quoted string"
+//
+// e.g. bio:("golang" OR rust) AND NOT location:"remote" AND followers:1000..50000
+// a field applies to every term inside its group, ranges are FROM..TO
+// and any side of the range can be omitted, field:-term is field:(NOT term).
+
+const dateLayout = "2006-01-02"
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokMinus
+	tokField
+	tokWord
+	tokString
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return strconv.Quote(t.text)
+	case tokField:
+		return t.text + ":"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex : split the query into tokens
+func lex(q string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, token{kind: tokMinus, text: "-", pos: i})
+			i++
+		case r == '"':
+			start := i
+			var b strings.Builder
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated quoted string at position %v", start)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokString, text: b.String(), pos: start})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()"`, runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, text: word, pos: start})
+				continue
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, text: word, pos: start})
+				continue
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, text: word, pos: start})
+				continue
+			}
+			if idx := strings.IndexByte(word, ':'); idx > 0 {
+				if _, ok := fields[strings.ToLower(word[:idx])]; ok {
+					tokens = append(tokens, token{kind: tokField, text: strings.ToLower(word[:idx]), pos: start})
+					rest, pos := word[idx+1:], start+len([]rune(word[:idx]))+1
+					// field:-term is field:(NOT term), quoted keywords can start with '-' e.g. bio:"-term"
+					if strings.HasPrefix(rest, "-") && (len(rest) > 1 || (i < len(runes) && strings.ContainsRune(`("`, runes[i]))) {
+						tokens = append(tokens, token{kind: tokMinus, text: "-", pos: pos})
+						rest, pos = rest[1:], pos+1
+					}
+					if rest != "" {
+						tokens = append(tokens, token{kind: tokWord, text: rest, pos: pos})
+					}
+					continue
+				}
+			}
+			tokens = append(tokens, token{kind: tokWord, text: word, pos: start})
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	fold   func(string) string
+}
+
+// parseQuery : parse the query into expression tree
+func parseQuery(q string, fold func(string) string) (node, error) {
+	tokens, err := lex(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, fold: fold}
+	n, err := p.parseOr("")
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %v at position %v", t, t.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr(fieldName string) (node, error) {
+	n, err := p.parseAnd(fieldName)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokOr {
+		return n, nil
+	}
+	or := &orNode{children: []node{n}}
+	for p.peek().kind == tokOr {
+		p.next()
+		n, err := p.parseAnd(fieldName)
+		if err != nil {
+			return nil, err
+		}
+		or.children = append(or.children, n)
+	}
+	return or, nil
+}
+
+func (p *parser) parseAnd(fieldName string) (node, error) {
+	n, err := p.parseUnary(fieldName)
+	if err != nil {
+		return nil, err
+	}
+	and := &andNode{children: []node{n}}
+	for {
+		switch p.peek().kind {
+		case tokEOF, tokRParen, tokOr:
+			if len(and.children) == 1 {
+				return n, nil
+			}
+			return and, nil
+		case tokAnd:
+			p.next()
+		}
+		n, err := p.parseUnary(fieldName)
+		if err != nil {
+			return nil, err
+		}
+		and.children = append(and.children, n)
+	}
+}
+
+func (p *parser) parseUnary(fieldName string) (node, error) {
+	switch p.peek().kind {
+	case tokNot, tokMinus:
+		p.next()
+		n, err := p.parseUnary(fieldName)
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{child: n}, nil
+	}
+	return p.parsePrimary(fieldName)
+}
+
+func (p *parser) parsePrimary(fieldName string) (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		n, err := p.parseOr(fieldName)
+		if err != nil {
+			return nil, err
+		}
+		if r := p.next(); r.kind != tokRParen {
+			return nil, fmt.Errorf("expected ')' at position %v, found %v", r.pos, r)
+		}
+		return n, nil
+	case tokField:
+		return p.parseUnary(t.text)
+	case tokWord, tokString:
+		if fieldName == "" {
+			return nil, fmt.Errorf("missing field for %v at position %v", t, t.pos)
+		}
+		n, err := p.newTerm(fieldName, t)
+		if err != nil {
+			return nil, fmt.Errorf("%v at position %v", err, t.pos)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("unexpected %v at position %v", t, t.pos)
+}
+
+// newTerm : build the expression node for the field value
+// text values are keywords, quoted values are phrases
+// and mode:"quoted value" use the mode with the quoted value.
+func (p *parser) newTerm(fieldName string, t token) (node, error) {
+	value := t.text
+	f := fields[fieldName]
+	switch f.kind {
+	case numberField:
+		from, to, err := splitRange(value)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		between := config.FromToNumber{}
+		if between.From, err = parseRangeBound(from, parseNumber); err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		if between.To, err = parseRangeBound(to, parseNumber); err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		return &numberNode{field: fieldName, between: between}, nil
+	case floatField:
+		from, to, err := splitRange(value)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		between := config.FromToFloat{}
+		if between.From, err = parseRangeBound(from, parseFloat); err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		if between.To, err = parseRangeBound(to, parseFloat); err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		return &floatNode{field: fieldName, between: between}, nil
+	case dateField:
+		from, to, err := splitRange(value)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		between := config.FromToDate{}
+		if between.From, err = parseRangeBound(from, parseDate); err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		if between.To, err = parseRangeBound(to, parseDate); err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		return &dateNode{field: fieldName, between: between}, nil
+	case boolField:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%v: expected true or false, found %q", fieldName, value)
+		}
+		return &boolNode{field: fieldName, value: v}, nil
+	case countryField:
+		code, err := parseCountry(value)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		return &countryNode{field: fieldName, code: code}, nil
+	case nearField:
+		// near:Berlin~50 or near:"New York"~25
+		center, km := value, ""
+		if idx := strings.LastIndexByte(value, '~'); t.kind == tokWord && idx >= 0 {
+			center, km = value[:idx], value[idx+1:]
+		} else if w := p.peek(); t.kind == tokString && w.kind == tokWord && strings.HasPrefix(w.text, "~") {
+			km = p.next().text[1:]
+		}
+		distance, err := strconv.ParseFloat(km, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%v: expected CITY~KM, found %q", fieldName, value)
+		}
+		n, err := newNearNode(fieldName, center, distance)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		return n, nil
+	case languageField:
+		n, err := newLanguageNode(fieldName, value)
+		if err == nil && n == nil {
+			err = fmt.Errorf("%v: empty language", fieldName)
+		}
+		return n, err
+	}
+	if f.kind == entityField {
+		n, err := keywordNode(fieldName, value, p.fold)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		return n, nil
+	}
+
+	// keyword file e.g. bio:file:companies.txt or bio:file:"my companies.txt"
+	if t.kind == tokWord && strings.HasPrefix(value, filePrefix) {
+		path := strings.TrimPrefix(value, filePrefix)
+		if path == "" && p.peek().kind == tokString {
+			path = p.next().text
+		}
+		n, err := fileExpression(fieldName, path, p.fold)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		return n, nil
+	}
+
+	var m *matcher
+	var err error
+	switch {
+	case t.kind == tokString:
+		m, err = newMatcher(phraseMode, value, p.fold)
+	case strings.HasSuffix(value, ":") && p.peek().kind == tokString:
+		mode, ok := matchModes[strings.ToLower(strings.TrimSuffix(value, ":"))]
+		if !ok {
+			return nil, fmt.Errorf("%v: unknown match mode %q", fieldName, value)
+		}
+		m, err = newMatcher(mode, p.next().text, p.fold)
+	default:
+		m, err = parseKeyword(value, p.fold)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", fieldName, err)
+	}
+	// threshold of quoted fuzzy value e.g. fuzzy:"Mohamed Ali"~2
+	if w := p.peek(); m.mode == fuzzyMode && w.kind == tokWord && strings.HasPrefix(w.text, "~") {
+		threshold, weight := splitWeight(w.text[1:])
+		if err := m.fuzzy.setThreshold(threshold); err != nil {
+			return nil, fmt.Errorf("%v: %v", fieldName, err)
+		}
+		p.next()
+		if weight != 0 {
+			m.weight = weight
+		}
+	}
+	// weight of quoted value e.g. "open source"^2
+	if w := p.peek(); w.kind == tokWord && strings.HasPrefix(w.text, "^") {
+		weight, err := strconv.ParseFloat(w.text[1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%v: invalid weight %q", fieldName, w.text)
+		}
+		p.next()
+		m.weight = weight
+	}
+	n, err := newTextNode(fieldName, m)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", fieldName, err)
+	}
+	return n, nil
+}
+
+// splitRange : split "FROM..TO" range
+func splitRange(value string) (string, string, error) {
+	idx := strings.Index(value, "..")
+	if idx < 0 {
+		return "", "", fmt.Errorf("expected range FROM..TO, found %q", value)
+	}
+	return value[:idx], value[idx+2:], nil
+}
+
+func parseRangeBound[T any](value string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	if value == "" {
+		return zero, nil
+	}
+	return parse(value)
+}
+
+func parseNumber(value string) (int64, error) {
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected number, found %q", value)
+	}
+	return v, nil
+}
+
+func parseFloat(value string) (float64, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected number, found %q", value)
+	}
+	return v, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected date %v, found %q", dateLayout, value)
+	}
+	return t, nil
+}
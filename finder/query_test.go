@@ -0,0 +1,122 @@
+package finder
+
+import (
+	"strings"
+	"testing"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`bio:golang`, `bio:"golang"`},
+		{`BIO:golang`, `bio:"golang"`},
+		{`bio:golang location:berlin`, `(bio:"golang" AND location:"berlin")`},
+		{`bio:golang AND location:berlin OR name:gopher`, `((bio:"golang" AND location:"berlin") OR name:"gopher")`},
+		{`bio:(golang OR rust) location:berlin`, `((bio:"golang" OR bio:"rust") AND location:"berlin")`},
+		{`bio:("golang" OR rust)`, `(bio:phrase:"golang" OR bio:"rust")`},
+		{`NOT bio:go AND followers:10..`, `(NOT bio:"go" AND followers:10..)`},
+		{`-bio:recruiter`, `NOT bio:"recruiter"`},
+		{`NOT NOT bio:go`, `NOT NOT bio:"go"`},
+		{`followers:..100 OR verified:true`, `(followers:..100 OR verified:true)`},
+		{`followers:1000..50000`, `followers:1000..50000`},
+		{`joined:2020-01-01..`, `joined:2020-01-01..`},
+		{`bio:go^2`, `bio:"go"^2`},
+		{`bio:regex:"go(lang)?"`, `bio:regex:"go(lang)?"`},
+		// field:-term is field:(NOT term)
+		{`bio:-recruiter`, `NOT bio:"recruiter"`},
+		{`bio:golang bio:-recruiter`, `(bio:"golang" AND NOT bio:"recruiter")`},
+		{`bio:-"open source"`, `NOT bio:phrase:"open source"`},
+		{`bio:-(recruiter OR hiring)`, `NOT (bio:"recruiter" OR bio:"hiring")`},
+		// quoted keywords keep the '-'
+		{`bio:"-recruiter"`, `bio:phrase:"-recruiter"`},
+	}
+	for _, tt := range tests {
+		n, err := parseQuery(tt.query, normalize)
+		if err != nil {
+			t.Errorf("%v: %v", tt.query, err)
+			continue
+		}
+		if n.String() != tt.expected {
+			t.Errorf("%v: parsed %v, expected %v", tt.query, n, tt.expected)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{`bio:`, "unexpected end of query at position 4"},
+		{`NOT`, "unexpected end of query at position 3"},
+		{`bio:go AND`, "unexpected end of query at position 10"},
+		{`bio:go OR`, "unexpected end of query at position 9"},
+		{`(bio:go`, "expected ')' at position 7, found end of query"},
+		{`bio:(go OR rust`, "expected ')' at position 15, found end of query"},
+		{`bio:go)`, `unexpected ")" at position 6`},
+		{`()`, `unexpected ")" at position 1`},
+		{`go`, `missing field for "go" at position 0`},
+		{`unknown:x`, `missing field for "unknown:x" at position 0`},
+		{`bio:"open`, "unterminated quoted string at position 4"},
+		{`followers:abc`, `followers: expected range FROM..TO, found "abc"`},
+		{`followers:1..x`, `followers: expected number, found "x"`},
+		{`joined:2020..`, `joined: expected date 2006-01-02, found "2020"`},
+		{`verified:maybe`, "verified: expected true or false"},
+	}
+	for _, tt := range tests {
+		_, err := parseQuery(tt.query, normalize)
+		if err == nil {
+			t.Errorf("%v: parsed, expected error %q", tt.query, tt.err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%v: error %q, expected %q", tt.query, err, tt.err)
+		}
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	recordErrors(t)
+	users := map[string]anaconda.User{
+		"gopher":    {ScreenName: "gopher", Description: "Golang developer", Location: "Berlin", FollowersCount: 500},
+		"recruiter": {ScreenName: "hiring", Description: "Golang recruiter, we are hiring", Location: "Berlin", FollowersCount: 5000},
+		"rustacean": {ScreenName: "crab", Description: "Rust and open source", Location: "Paris", FollowersCount: 50},
+		"verified":  {ScreenName: "famous", Description: "Open source maintainer", Location: "Berlin", FollowersCount: 90000, Verified: true},
+	}
+	tests := []struct {
+		query   string
+		matched []string
+	}{
+		{`bio:golang`, []string{"gopher", "recruiter"}},
+		{`bio:golang bio:-recruiter`, []string{"gopher"}},
+		{`bio:golang -bio:recruiter`, []string{"gopher"}},
+		{`bio:(golang OR rust) location:-paris`, []string{"gopher", "recruiter"}},
+		{`bio:-(golang OR rust)`, []string{"verified"}},
+		{`bio:"open source"`, []string{"rustacean", "verified"}},
+		{`location:berlin AND followers:1000..`, []string{"recruiter", "verified"}},
+		{`followers:..1000 OR verified:true`, []string{"gopher", "rustacean", "verified"}},
+		{`NOT (location:berlin OR followers:..100)`, nil},
+	}
+	for _, tt := range tests {
+		f, err := NewFinder(config.SearchCriteria{Query: tt.query})
+		if err != nil {
+			t.Errorf("%v: %v", tt.query, err)
+			continue
+		}
+		expected := map[string]bool{}
+		for _, name := range tt.matched {
+			expected[name] = true
+		}
+		for name, u := range users {
+			u := u
+			if report := f.Match(&u); report.Matched != expected[name] {
+				t.Errorf("%v: %v matched %v, expected %v: %v", tt.query, name, report.Matched, expected[name], report)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package finder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"twfinder/config"
+)
+
+// FilterFactory : build the custom filter from its CUSTOM_FILTERS parameters
+type FilterFactory func(params json.RawMessage) (Filter, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]FilterFactory{}
+)
+
+// RegisterFilter : make a custom filter available by name to the CUSTOM_FILTERS configuration,
+// usually called from the init function of the package that implements the filter.
+// it panics if the name is empty, already registered or the factory is nil.
+func RegisterFilter(name string, factory func(json.RawMessage) (Filter, error)) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" || factory == nil {
+		panic("finder: RegisterFilter with empty name or nil factory")
+	}
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("finder: RegisterFilter called twice for filter %q", name))
+	}
+	registry[name] = factory
+}
+
+// RegisteredFilters : names of all the registered custom filters
+func RegisteredFilters() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// customFilter : build the registered filter with the configured parameters,
+// the result filter name is the registered name if the filter didn't set it.
+func customFilter(c config.CustomFilter) (profileFilter, string, error) {
+	name := strings.ToUpper(strings.TrimSpace(c.Name))
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, "", fmt.Errorf("unknown custom filter %q, registered filters: %v", c.Name, RegisteredFilters())
+	}
+	f, err := factory(c.Params)
+	if err != nil {
+		return nil, "", fmt.Errorf("error occurred during build the custom filter %q: %v", name, err)
+	}
+	if f == nil {
+		return nil, "", fmt.Errorf("custom filter %q factory returned nil filter", name)
+	}
+	return func(u *profile) FilterResult {
+		res := f.Match(u.User)
+		if res.Filter == "" {
+			res.Filter = name
+		}
+		return res
+	}, name, nil
+}
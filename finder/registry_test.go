@@ -0,0 +1,142 @@
+package finder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// minFollowersFactory : custom filter with the minimum followers as parameter
+func minFollowersFactory(params json.RawMessage) (Filter, error) {
+	var p struct {
+		Min int `json:"MIN"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Min < 0 {
+		return nil, errors.New("MIN should not be negative")
+	}
+	return FilterFunc(func(u *anaconda.User) FilterResult {
+		return FilterResult{Passed: u.FollowersCount >= p.Min, Decider: fmt.Sprintf("%v..", p.Min), Value: fmt.Sprint(u.FollowersCount)}
+	}), nil
+}
+
+// registerTestFilter : register the filter until the end of the test
+func registerTestFilter(t *testing.T, name string, factory FilterFactory) {
+	t.Helper()
+	RegisterFilter(name, factory)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(registry, strings.ToUpper(strings.TrimSpace(name)))
+	})
+}
+
+func TestRegisterFilter(t *testing.T) {
+	recordErrors(t)
+	registerTestFilter(t, " test_min_followers ", minFollowersFactory)
+	found := false
+	for _, name := range RegisteredFilters() {
+		found = found || name == "TEST_MIN_FOLLOWERS"
+	}
+	if !found {
+		t.Fatalf("registered filters %v, expected TEST_MIN_FOLLOWERS", RegisteredFilters())
+	}
+
+	f, err := NewFinder(config.SearchCriteria{
+		SearchBioContext: []string{"golang"},
+		CustomFilters:    []config.CustomFilter{{Name: "test_min_followers", Params: json.RawMessage(`{"MIN": 100}`)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		user    anaconda.User
+		matched bool
+	}{
+		{anaconda.User{Description: "golang", FollowersCount: 500}, true},
+		{anaconda.User{Description: "golang", FollowersCount: 50}, false},
+		{anaconda.User{Description: "rust", FollowersCount: 500}, false},
+	}
+	for _, tt := range tests {
+		u := tt.user
+		report := f.Match(&u)
+		if report.Matched != tt.matched {
+			t.Errorf("%q %v followers matched %v, expected %v: %v", u.Description, u.FollowersCount, report.Matched, tt.matched, report)
+		}
+		// the result is named after the registered filter
+		last := report.Filters[len(report.Filters)-1]
+		if last.Filter != "TEST_MIN_FOLLOWERS" || last.Decider != "100.." {
+			t.Errorf("custom filter result %v, expected TEST_MIN_FOLLOWERS [100..]", last)
+		}
+	}
+}
+
+func TestRegisterFilterKeepsResultName(t *testing.T) {
+	recordErrors(t)
+	registerTestFilter(t, "test_named", func(json.RawMessage) (Filter, error) {
+		return FilterFunc(func(u *anaconda.User) FilterResult {
+			return FilterResult{Filter: "DEFAULT_BIO", Passed: u.Description == ""}
+		}), nil
+	})
+	f, err := NewFinder(config.SearchCriteria{CustomFilters: []config.CustomFilter{{Name: "TEST_NAMED"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := anaconda.User{}
+	report := f.Match(&u)
+	if last := report.Filters[len(report.Filters)-1]; !report.Matched || last.Filter != "DEFAULT_BIO" {
+		t.Errorf("custom filter result %v, expected DEFAULT_BIO passed", report)
+	}
+}
+
+func TestRegisterFilterPanics(t *testing.T) {
+	registerTestFilter(t, "test_twice", minFollowersFactory)
+	tests := []struct {
+		name    string
+		factory FilterFactory
+	}{
+		{"test_twice", minFollowersFactory},
+		{"TEST_TWICE ", minFollowersFactory},
+		{" ", minFollowersFactory},
+		{"test_nil", nil},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterFilter(%q) did not panic", tt.name)
+				}
+			}()
+			RegisterFilter(tt.name, tt.factory)
+		}()
+	}
+}
+
+func TestCustomFilterErrors(t *testing.T) {
+	registerTestFilter(t, "test_failing", func(json.RawMessage) (Filter, error) { return nil, errors.New("no connection") })
+	registerTestFilter(t, "test_nil_filter", func(json.RawMessage) (Filter, error) { return nil, nil })
+	registerTestFilter(t, "test_params", minFollowersFactory)
+	tests := []struct {
+		filter config.CustomFilter
+		err    string
+	}{
+		{config.CustomFilter{Name: "test_unknown"}, `unknown custom filter "test_unknown"`},
+		{config.CustomFilter{Name: "test_failing"}, `"TEST_FAILING": no connection`},
+		{config.CustomFilter{Name: "test_nil_filter"}, `"TEST_NIL_FILTER" factory returned nil filter`},
+		{config.CustomFilter{Name: "test_params", Params: json.RawMessage(`{"MIN": -1}`)}, "MIN should not be negative"},
+		{config.CustomFilter{Name: "test_params", Params: json.RawMessage(`{"MIN": "x"}`)}, `"TEST_PARAMS"`},
+	}
+	for _, tt := range tests {
+		_, err := NewFinder(config.SearchCriteria{CustomFilters: []config.CustomFilter{tt.filter}})
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%v: error %v, expected %v", tt.filter.Name, err, tt.err)
+		}
+	}
+}
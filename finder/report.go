@@ -0,0 +1,104 @@
+package finder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// FilterResult : the result of one filter against the user profile
+type FilterResult struct {
+	// Filter : filter name e.g. "BIO", "LOCATION"
+	Filter string `json:"FILTER"`
+	Passed bool   `json:"PASSED"`
+	// Decider : the keyword or range bound that decided the result
+	Decider string `json:"DECIDER"`
+	// Value : the profile field value the filter checked
+	Value string `json:"VALUE"`
+	// Weight : the weight of the keyword that decided the result
+	Weight float64 `json:"WEIGHT,omitempty"`
+	// Score : the filter weight * keyword weight if passed (scoring mode)
+	Score float64 `json:"SCORE"`
+}
+
+// MatchReport : explain why the user has been matched or not
+type MatchReport struct {
+	Matched bool           `json:"MATCHED"`
+	Score   float64        `json:"SCORE"`
+	Filters []FilterResult `json:"FILTERS"`
+	// Language : detected language of the bio and name
+	Language Language `json:"LANGUAGE"`
+	// Place : resolved location of the user
+	Place Place `json:"PLACE"`
+	// Bot : bot and spam likelihood with the signals breakdown
+	Bot BotScore `json:"BOT"`
+	// profile : the user matched and the values computed by Match, reused by Describe
+	profile *profile
+}
+
+// Describe : add the language, place and bot score that the criteria did not use to the report
+// e.g. before storing the matched user, the values computed by Match are not computed again.
+func (r *MatchReport) Describe(u *anaconda.User) {
+	if r.profile == nil || r.profile.User != u {
+		r.profile = newProfile(u, nil)
+	}
+	r.Language = r.profile.languageOf()
+	r.Place = r.profile.placeOf()
+	r.Bot = r.profile.botOf()
+}
+
+// keywordWeight : weight of the decider keyword, default 1
+func (r FilterResult) keywordWeight() float64 {
+	if r.Weight == 0 {
+		return 1
+	}
+	return r.Weight
+}
+
+func (r FilterResult) String() string {
+	res := "FAIL"
+	if r.Passed {
+		res = "PASS"
+	}
+	return fmt.Sprintf("%v %v [%v] %q", res, r.Filter, r.Decider, r.Value)
+}
+
+func (r MatchReport) String() string {
+	parts := make([]string, 0, len(r.Filters))
+	for _, f := range r.Filters {
+		parts = append(parts, f.String())
+	}
+	return fmt.Sprintf("score:%v %v", r.Score, strings.Join(parts, ", "))
+}
+
+// combineResults : result of AND/OR when no child decided it alone
+// e.g. all the keywords of OR failed, the decider is all of them.
+func combineResults(results []FilterResult, op string, passed bool) FilterResult {
+	if len(results) == 0 {
+		return FilterResult{Filter: op, Passed: passed}
+	}
+	res := FilterResult{Filter: results[0].Filter, Passed: passed}
+	deciders := make([]string, 0, len(results))
+	values := make([]string, 0, len(results))
+	seenValues := map[string]bool{}
+	for _, r := range results {
+		if r.Filter != res.Filter {
+			res.Filter = op
+		}
+		if r.Weight > res.Weight {
+			res.Weight = r.Weight
+		}
+		deciders = append(deciders, r.Decider)
+		if !seenValues[r.Value] {
+			seenValues[r.Value] = true
+			values = append(values, r.Value)
+		}
+	}
+	res.Decider = strings.Join(deciders, " "+op+" ")
+	if len(deciders) > 1 {
+		res.Decider = "(" + res.Decider + ")"
+	}
+	res.Value = strings.Join(values, " | ")
+	return res
+}
@@ -0,0 +1,80 @@
+package finder
+
+import (
+	"testing"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+func TestMatchReport(t *testing.T) {
+	recordErrors(t)
+	criteria := config.SearchCriteria{
+		SearchBioContext:      []string{"golang^2", "rust"},
+		FollowersCountBetween: config.FromToNumber{From: 100},
+	}
+	scoring := criteria
+	scoring.Scoring = config.Scoring{Enabled: true, Threshold: 3, Weights: map[string]float64{"bio": 2}}
+
+	tests := []struct {
+		name     string
+		criteria config.SearchCriteria
+		user     anaconda.User
+		matched  bool
+		score    float64
+		filters  []FilterResult
+	}{
+		{"golang few followers", criteria, anaconda.User{Description: "Golang dev", FollowersCount: 50}, false, 2, []FilterResult{
+			{Filter: "PROTECTED", Passed: true, Decider: "false", Value: "false"},
+			{Filter: "BIO", Passed: true, Decider: `"golang"^2`, Value: "Golang dev", Weight: 2, Score: 2},
+			{Filter: "FOLLOWERS", Passed: false, Decider: "FROM 100", Value: "50"},
+		}},
+		{"rust", criteria, anaconda.User{Description: "Rust dev", FollowersCount: 500}, true, 2, []FilterResult{
+			{Filter: "PROTECTED", Passed: true, Decider: "false", Value: "false"},
+			{Filter: "BIO", Passed: true, Decider: `"rust"`, Value: "Rust dev", Weight: 1, Score: 1},
+			{Filter: "FOLLOWERS", Passed: true, Decider: "100..", Value: "500", Score: 1},
+		}},
+		{"no keyword", criteria, anaconda.User{Description: "java"}, false, 0, []FilterResult{
+			{Filter: "PROTECTED", Passed: true, Decider: "false", Value: "false"},
+			{Filter: "BIO", Passed: false, Decider: `("golang"^2 OR "rust")`, Value: "java", Weight: 2},
+			{Filter: "FOLLOWERS", Passed: false, Decider: "FROM 100", Value: "0"},
+		}},
+		// the BIO weight 2 * the keyword weight 2 reach the threshold alone
+		{"scoring golang few followers", scoring, anaconda.User{Description: "Golang dev", FollowersCount: 50}, true, 4, []FilterResult{
+			{Filter: "PROTECTED", Passed: true, Decider: "false", Value: "false"},
+			{Filter: "BIO", Passed: true, Decider: `"golang"^2`, Value: "Golang dev", Weight: 2, Score: 4},
+			{Filter: "FOLLOWERS", Passed: false, Decider: "FROM 100", Value: "50"},
+		}},
+		{"scoring rust", scoring, anaconda.User{Description: "Rust dev", FollowersCount: 500}, true, 3, []FilterResult{
+			{Filter: "PROTECTED", Passed: true, Decider: "false", Value: "false"},
+			{Filter: "BIO", Passed: true, Decider: `"rust"`, Value: "Rust dev", Weight: 1, Score: 2},
+			{Filter: "FOLLOWERS", Passed: true, Decider: "100..", Value: "500", Score: 1},
+		}},
+		// the failed gate is not scored in
+		{"scoring protected", scoring, anaconda.User{Description: "Golang dev", FollowersCount: 500, Protected: true}, false, 5, []FilterResult{
+			{Filter: "PROTECTED", Passed: false, Decider: "false", Value: "true"},
+			{Filter: "BIO", Passed: true, Decider: `"golang"^2`, Value: "Golang dev", Weight: 2, Score: 4},
+			{Filter: "FOLLOWERS", Passed: true, Decider: "100..", Value: "500", Score: 1},
+		}},
+	}
+	for _, tt := range tests {
+		f, err := NewFinder(tt.criteria)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		u := tt.user
+		report := f.Match(&u)
+		if report.Matched != tt.matched || report.Score != tt.score {
+			t.Errorf("%v: matched %v score %v, expected %v score %v: %v", tt.name, report.Matched, report.Score, tt.matched, tt.score, report)
+		}
+		if len(report.Filters) != len(tt.filters) {
+			t.Errorf("%v: %v filters, expected %v: %v", tt.name, len(report.Filters), len(tt.filters), report)
+			continue
+		}
+		for i, res := range report.Filters {
+			if res != tt.filters[i] {
+				t.Errorf("%v: filter %v is %#v, expected %#v", tt.name, i, res, tt.filters[i])
+			}
+		}
+	}
+}
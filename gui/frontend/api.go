@@ -0,0 +1,55 @@
+package frontend
+
+import (
+	"encoding/json"
+	"net/http"
+	"twfinder/logger"
+)
+
+// RegisterAPI : register the HTTP endpoints of the running pipeline with the GUI server
+// POST /api/pause, POST /api/resume and GET /api/stats
+func RegisterAPI() {
+	http.HandleFunc("/api/pause", pauseHandler(PausePipeline))
+	http.HandleFunc("/api/resume", pauseHandler(ResumePipeline))
+	http.HandleFunc("/api/stats", statsHandler)
+}
+
+// statsHandler : respond with the stats of the running pipeline or the last one
+func statsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	st, err := PipelineStats()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, st)
+}
+
+// pauseHandler : respond with {"PAUSED": true|false} after the action
+func pauseHandler(action func() (bool, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		paused, err := action()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSON(w, map[string]bool{"PAUSED": paused})
+	}
+}
+
+// writeJSON : respond with the value as JSON
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error(err)
+	}
+}
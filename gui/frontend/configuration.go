@@ -1,12 +1,17 @@
 package frontend
 
 import (
+	"fmt"
 	"math/rand"
 	"strconv"
+	"strings"
 	"time"
 	"twfinder/config"
 	"twfinder/gui/server"
 	"twfinder/logger"
+	"twfinder/pipeline"
+	"twfinder/request"
+	"twfinder/storage"
 )
 
 // newStrTxtLblPanel : create new TextBox with lable in Horizontal mode
@@ -52,6 +57,30 @@ func newIntTxtLblPanel(lbltxt string, intInput *int64) server.Panel {
 	return pan
 }
 
+// newFloatTxtLblPanel : create new TextBox with lable in Horizontal mode
+// floatInput : float input for TextBox
+func newFloatTxtLblPanel(lbltxt string, floatInput *float64) server.Panel {
+	pan := server.NewHorizontalPanel()
+
+	lbl := server.NewLabel(lbltxt)
+	pan.Add(lbl)
+
+	inputStr := strconv.FormatFloat(*floatInput, 'f', -1, 64)
+	txtbox := server.NewTextBox(inputStr)
+	txtbox.AddEHandlerFunc(func(e server.Event) {
+		eventText := txtbox.Text()
+		f, err := strconv.ParseFloat(eventText, 64)
+		if err != nil {
+			logger.Errorf("error occurred during conver string to number input:%v  error:%v", eventText, err)
+			return
+		}
+		*floatInput = f
+	}, server.ETypeChange)
+	pan.Add(txtbox)
+
+	return pan
+}
+
 // newDatepickerLblPanel : create new datepicker with lable in Horizontal mode
 // dateInput : date input for datepicker
 func newDatepickerLblPanel(lbltxt string, dateInput *time.Time) server.Panel {
@@ -126,6 +155,66 @@ func newIntTextBoxFromTo(panalTitle string, from *int64, to *int64) server.Panel
 	return mainPanal
 }
 
+// newFloatTextBoxFromTo : return new two float only text box
+// to handle from/to input
+func newFloatTextBoxFromTo(panalTitle string, from *float64, to *float64) server.Panel {
+	// main
+	mainPanal := server.NewVerticalPanel()
+
+	// header
+	headerPanel := server.NewHorizontalPanel()
+	headerPanellbl := server.NewLabel(panalTitle)
+	headerPanel.Add(headerPanellbl)
+
+	// body
+	bodyPanelfunc := func(from *float64, to *float64) server.Panel {
+		pan := server.NewVerticalPanel()
+		fromPan := newFloatTxtLblPanel("From", from)
+		pan.Add(fromPan)
+		toPan := newFloatTxtLblPanel("To", to)
+		pan.Add(toPan)
+		return pan
+	}
+	bodyPanel := bodyPanelfunc(from, to)
+
+	// btns
+	headerPanelAddBtn := server.NewButton("+")
+	headerPanelRemoveBtn := server.NewButton("-")
+
+	// addbtn
+	headerPanelAddBtn.AddSyncOnETypes(server.ETypeClick)
+	headerPanelAddBtn.AddEHandlerFunc(func(e server.Event) {
+		bodyPanel = bodyPanelfunc(from, to)
+		mainPanal.Add(bodyPanel)
+		headerPanel.Insert(headerPanelRemoveBtn, headerPanel.CompsCount())
+		headerPanel.Remove(headerPanelAddBtn)
+		e.MarkDirty(mainPanal, headerPanel)
+	}, server.ETypeClick)
+
+	// removebtn
+	headerPanelRemoveBtn.AddSyncOnETypes(server.ETypeClick)
+	headerPanelRemoveBtn.AddEHandlerFunc(func(e server.Event) {
+		// update values
+		*from = 0
+		*to = 0
+		mainPanal.Remove(bodyPanel)
+		headerPanel.Insert(headerPanelAddBtn, headerPanel.CompsCount())
+		headerPanel.Remove(headerPanelRemoveBtn)
+		e.MarkDirty(mainPanal, headerPanel)
+	}, server.ETypeClick)
+
+	mainPanal.Add(headerPanel)
+
+	if *from > 0 || *to > 0 {
+		headerPanel.Add(headerPanelRemoveBtn)
+		mainPanal.Add(bodyPanel)
+	} else {
+		headerPanel.Add(headerPanelAddBtn)
+	}
+
+	return mainPanal
+}
+
 // newDatepickerFromTo : return new two Datepicker
 // to handle from/to input
 func newDatepickerFromTo(panalTitle string, from *time.Time, to *time.Time) server.Panel {
@@ -241,6 +330,15 @@ func newArrTextBoxPanal(panalTitle string, inputArr []string) (server.Panel, map
 	return mainPanal, mainMap
 }
 
+// mapValues : values of newArrTextBoxPanal map
+func mapValues(m map[int]string) []string {
+	var res []string
+	for _, v := range m {
+		res = append(res, v)
+	}
+	return res
+}
+
 // newCheckPanel : create new checkbos with lable in Horizontal mode
 // state : State of the checkbox
 func newCheckPanel(lbltxt string, state *bool) server.Panel {
@@ -256,6 +354,37 @@ func newCheckPanel(lbltxt string, state *bool) server.Panel {
 	return pan
 }
 
+// newListBoxLblPanel : create new drop-down list with lable in Horizontal mode
+// selected : the selected value, empty value selects the first one
+func newListBoxLblPanel(lbltxt string, values []string, selected *string) server.Panel {
+	pan := server.NewHorizontalPanel()
+	lbl := server.NewLabel(lbltxt)
+	pan.Add(lbl)
+	listBox := server.NewListBox(values)
+	listBox.SetSelected(0, true)
+	for i, v := range values {
+		if strings.EqualFold(v, *selected) {
+			listBox.SetSelected(i, true)
+		}
+	}
+	listBox.AddEHandlerFunc(func(e server.Event) {
+		*selected = listBox.SelectedValue()
+	}, server.ETypeChange)
+	pan.Add(listBox)
+	return pan
+}
+
+// newTriStatePanel : create new REQUIRE/EXCLUDE/IGNORE drop-down list with lable in Horizontal mode
+// def : the selected value if the state is not set
+func newTriStatePanel(lbltxt string, state *config.TriState, def config.TriState) server.Panel {
+	*state = state.Or(def)
+	values := make([]string, 0, len(config.TriStates))
+	for _, v := range config.TriStates {
+		values = append(values, string(v))
+	}
+	return newListBoxLblPanel(lbltxt, values, (*string)(state))
+}
+
 // ConfigWin : build configuration window with all required elements
 func ConfigWin() server.Window {
 	twitterConfig := config.Configuration()
@@ -279,8 +408,13 @@ func ConfigWin() server.Window {
 	accessTokenSecretPan := newStrTxtLblPanel("Access Token Secret", &twitterConfig.AccessTokenSecret, true)
 	win.Add(accessTokenSecretPan)
 
-	searchUserPan := newStrTxtLblPanel("Search User", &twitterConfig.SearchUser, false)
-	win.Add(searchUserPan)
+	// seedsPanal : SEARCH_USER is edited as the first seed
+	seeds := twitterConfig.Seeds
+	if strings.TrimSpace(twitterConfig.SearchUser) != "" {
+		seeds = append([]string{twitterConfig.SearchUser}, seeds...)
+	}
+	seedsPanal, seedsMainMap := newArrTextBoxPanal("Seeds (handle, id:ID, list:OWNER/SLUG, following_not_followers:handle, file:path)", seeds)
+	win.Add(seedsPanal)
 	//
 	// ---
 	//
@@ -309,6 +443,35 @@ func ConfigWin() server.Window {
 	// searchLocationPanal
 	searchLocationPanal, locationMainMap := newArrTextBoxPanal("Search Location Context", twitterConfig.SearchCriteria.SearchLocationContext)
 	win.Add(searchLocationPanal)
+	// locationCountriesPanal e.g. "DE", "AT"
+	locationCountriesPanal, locationCountriesMainMap := newArrTextBoxPanal("Location Countries", twitterConfig.SearchCriteria.LocationCountries)
+	win.Add(locationCountriesPanal)
+	// locationNearPan city or "LAT,LON"
+	locationNearPan := newStrTxtLblPanel("Location Near (city)", &twitterConfig.SearchCriteria.LocationNear.Place, false)
+	win.Add(locationNearPan)
+	// locationNearKmPan
+	locationNearKmPan := newFloatTxtLblPanel("Location Near Within (km)", &twitterConfig.SearchCriteria.LocationNear.Km)
+	win.Add(locationNearKmPan)
+	// entityPanals
+	profileURLPanal, profileURLMainMap := newArrTextBoxPanal("Profile URL Domains", twitterConfig.SearchCriteria.ProfileURLDomains)
+	win.Add(profileURLPanal)
+	bioURLPanal, bioURLMainMap := newArrTextBoxPanal("Bio URL Domains", twitterConfig.SearchCriteria.BioURLDomains)
+	win.Add(bioURLPanal)
+	bioMentionsPanal, bioMentionsMainMap := newArrTextBoxPanal("Bio Mentions", twitterConfig.SearchCriteria.BioMentions)
+	win.Add(bioMentionsPanal)
+	bioHashtagsPanal, bioHashtagsMainMap := newArrTextBoxPanal("Bio Hashtags", twitterConfig.SearchCriteria.BioHashtags)
+	win.Add(bioHashtagsPanal)
+	bioCashtagsPanal, bioCashtagsMainMap := newArrTextBoxPanal("Bio Cashtags", twitterConfig.SearchCriteria.BioCashtags)
+	win.Add(bioCashtagsPanal)
+	bioEmailsPanal, bioEmailsMainMap := newArrTextBoxPanal("Bio Emails", twitterConfig.SearchCriteria.BioEmails)
+	win.Add(bioEmailsPanal)
+
+	// languagesPanal e.g. "de", "ar"
+	languagesPanal, languagesMainMap := newArrTextBoxPanal("Languages", twitterConfig.SearchCriteria.Languages)
+	win.Add(languagesPanal)
+	// languageConfidencePan
+	languageConfidencePan := newFloatTxtLblPanel("Language Confidence (0-1)", &twitterConfig.SearchCriteria.LanguageConfidence)
+	win.Add(languageConfidencePan)
 	// followersPanal
 	followersPanal := newIntTextBoxFromTo("Followers Count Between", &twitterConfig.SearchCriteria.FollowersCountBetween.From, &twitterConfig.SearchCriteria.FollowersCountBetween.To)
 	win.Add(followersPanal)
@@ -329,9 +492,78 @@ func ConfigWin() server.Window {
 	JoinDatePanal := newDatepickerFromTo("Joined Date Between", &twitterConfig.SearchCriteria.JoinedBetween.From, &twitterConfig.SearchCriteria.JoinedBetween.To)
 	win.Add(JoinDatePanal)
 
-	// verifiedCb
-	verifiedCb := newCheckPanel("Verified", &twitterConfig.SearchCriteria.Verified)
-	win.Add(verifiedCb)
+	// lastTweetedPanal
+	lastTweetedPanal := newDatepickerFromTo("Last Tweeted Between", &twitterConfig.SearchCriteria.LastTweetedBetween.From, &twitterConfig.SearchCriteria.LastTweetedBetween.To)
+	win.Add(lastTweetedPanal)
+	// excludeInactivePan
+	excludeInactivePan := newIntTxtLblPanel("Exclude Inactive More Than (days)", &twitterConfig.SearchCriteria.ExcludeInactiveDays)
+	win.Add(excludeInactivePan)
+	// missingStatusPan
+	missingStatusPan := newListBoxLblPanel("Users Without Status", []string{config.MissingStatusExclude, config.MissingStatusInclude}, &twitterConfig.SearchCriteria.MissingStatus)
+	win.Add(missingStatusPan)
+
+	// followersRatioPanal
+	followersRatioPanal := newFloatTextBoxFromTo("Followers/Following Ratio Between", &twitterConfig.SearchCriteria.FollowersRatioBetween.From, &twitterConfig.SearchCriteria.FollowersRatioBetween.To)
+	win.Add(followersRatioPanal)
+	// accountAgePanal
+	accountAgePanal := newIntTextBoxFromTo("Account Age (days) Between", &twitterConfig.SearchCriteria.AccountAgeDaysBetween.From, &twitterConfig.SearchCriteria.AccountAgeDaysBetween.To)
+	win.Add(accountAgePanal)
+	// tweetsPerDayPanal
+	tweetsPerDayPanal := newFloatTextBoxFromTo("Tweets Per Day Between", &twitterConfig.SearchCriteria.TweetsPerDayBetween.From, &twitterConfig.SearchCriteria.TweetsPerDayBetween.To)
+	win.Add(tweetsPerDayPanal)
+	// likesPerTweetPanal
+	likesPerTweetPanal := newFloatTextBoxFromTo("Likes Per Tweet Between", &twitterConfig.SearchCriteria.LikesPerTweetBetween.From, &twitterConfig.SearchCriteria.LikesPerTweetBetween.To)
+	win.Add(likesPerTweetPanal)
+	// listedPer1KFollowersPanal
+	listedPer1KFollowersPanal := newFloatTextBoxFromTo("Listed Per 1K Followers Between", &twitterConfig.SearchCriteria.ListedPer1KFollowersBetween.From, &twitterConfig.SearchCriteria.ListedPer1KFollowersBetween.To)
+	win.Add(listedPer1KFollowersPanal)
+	// maxBotScorePan
+	maxBotScorePan := newFloatTxtLblPanel("Max Bot Score (0-1)", &twitterConfig.SearchCriteria.MaxBotScore)
+	win.Add(maxBotScorePan)
+
+	// verifiedPan
+	verifiedPan := newTriStatePanel("Verified", &twitterConfig.SearchCriteria.Verified, config.TriStateIgnore)
+	win.Add(verifiedPan)
+	// protectedPan
+	protectedPan := newTriStatePanel("Protected", &twitterConfig.SearchCriteria.Protected, config.TriStateExclude)
+	win.Add(protectedPan)
+	// defaultProfilePan
+	defaultProfilePan := newTriStatePanel("Default Profile", &twitterConfig.SearchCriteria.DefaultProfile, config.TriStateIgnore)
+	win.Add(defaultProfilePan)
+	// defaultProfileImagePan
+	defaultProfileImagePan := newTriStatePanel("Default Profile Image", &twitterConfig.SearchCriteria.DefaultProfileImage, config.TriStateIgnore)
+	win.Add(defaultProfileImagePan)
+	// geoEnabledPan
+	geoEnabledPan := newTriStatePanel("Geo Enabled", &twitterConfig.SearchCriteria.GeoEnabled, config.TriStateIgnore)
+	win.Add(geoEnabledPan)
+
+	// queryPan
+	queryPan := newStrTxtLblPanel("Search Query", &twitterConfig.SearchCriteria.Query, false)
+	win.Add(queryPan)
+
+	// disableNormalizationCb
+	disableNormalizationCb := newCheckPanel("Disable Normalization", &twitterConfig.SearchCriteria.DisableNormalization)
+	win.Add(disableNormalizationCb)
+	//
+	// ---
+	//
+	win.Add(server.NewLabel("Scoring"))
+	// scoringCb
+	scoringCb := newCheckPanel("Scoring Mode", &twitterConfig.SearchCriteria.Scoring.Enabled)
+	win.Add(scoringCb)
+	// thresholdPan
+	thresholdPan := newFloatTxtLblPanel("Threshold", &twitterConfig.SearchCriteria.Scoring.Threshold)
+	win.Add(thresholdPan)
+	// recursiveThresholdPan
+	recursiveThresholdPan := newFloatTxtLblPanel("Recursive Threshold", &twitterConfig.SearchCriteria.Scoring.RecursiveThreshold)
+	win.Add(recursiveThresholdPan)
+	// weightsPanal "NAME=WEIGHT" e.g. "BIO=3"
+	weights := []string{}
+	for k, v := range twitterConfig.SearchCriteria.Scoring.Weights {
+		weights = append(weights, fmt.Sprintf("%v=%v", k, v))
+	}
+	weightsPanal, weightsMainMap := newArrTextBoxPanal("Weights (NAME=WEIGHT)", weights)
+	win.Add(weightsPanal)
 	//
 	// ---
 	//
@@ -347,6 +579,29 @@ func ConfigWin() server.Window {
 	// recursiveSuccessUsersOnlyCb
 	recursiveSuccessUsersOnlyCb := newCheckPanel("Recursive Success Users Only", &twitterConfig.RecursiveSuccessUsersOnly)
 	win.Add(recursiveSuccessUsersOnlyCb)
+	// maxDepthPan
+	maxDepthPan := newIntTxtLblPanel("Max Depth (0 unlimited)", &twitterConfig.MaxDepth)
+	win.Add(maxDepthPan)
+	// frontierOrderPan
+	frontierOrderPan := newListBoxLblPanel("Frontier Order", storage.FrontierOrders, &twitterConfig.FrontierOrder)
+	win.Add(frontierOrderPan)
+	//
+	// --- budget, 0 is unlimited
+	//
+	maxMatchesPan := newIntTxtLblPanel("Max Matches", &twitterConfig.Budget.MaxMatches)
+	win.Add(maxMatchesPan)
+	maxProfilesPan := newIntTxtLblPanel("Max Profiles", &twitterConfig.Budget.MaxProfiles)
+	win.Add(maxProfilesPan)
+	maxDurationPan := newIntTxtLblPanel("Max Duration (minutes)", &twitterConfig.Budget.MaxDurationMinutes)
+	win.Add(maxDurationPan)
+	maxDepthReachedPan := newIntTxtLblPanel("Stop At Depth", &twitterConfig.Budget.MaxDepthReached)
+	win.Add(maxDepthReachedPan)
+	maxAPICalls := make([]int64, len(request.Endpoints))
+	for i, endpoint := range request.Endpoints {
+		maxAPICalls[i] = twitterConfig.Budget.MaxAPICalls[endpoint]
+		maxAPICallsPan := newIntTxtLblPanel(fmt.Sprintf("Max API Calls %v", endpoint), &maxAPICalls[i])
+		win.Add(maxAPICallsPan)
+	}
 	//
 	// ---
 	//
@@ -369,6 +624,41 @@ func ConfigWin() server.Window {
 			twitterConfig.SearchCriteria.SearchLocationContext = append(twitterConfig.SearchCriteria.SearchLocationContext, v)
 		}
 
+		twitterConfig.SearchCriteria.ProfileURLDomains = mapValues(profileURLMainMap)
+		twitterConfig.SearchCriteria.BioURLDomains = mapValues(bioURLMainMap)
+		twitterConfig.SearchCriteria.BioMentions = mapValues(bioMentionsMainMap)
+		twitterConfig.SearchCriteria.BioHashtags = mapValues(bioHashtagsMainMap)
+		twitterConfig.SearchCriteria.BioCashtags = mapValues(bioCashtagsMainMap)
+		twitterConfig.SearchCriteria.BioEmails = mapValues(bioEmailsMainMap)
+		twitterConfig.SearchCriteria.Languages = mapValues(languagesMainMap)
+		twitterConfig.SearchCriteria.LocationCountries = mapValues(locationCountriesMainMap)
+		twitterConfig.SearchCriteria.Scoring.Weights = map[string]float64{}
+		for _, v := range weightsMainMap {
+			kv := strings.SplitN(v, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			w, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+			if err != nil {
+				logger.Errorf("error occurred during conver string to number input:%v  error:%v", v, err)
+				continue
+			}
+			twitterConfig.SearchCriteria.Scoring.Weights[strings.TrimSpace(kv[0])] = w
+		}
+
+		twitterConfig.Budget.MaxAPICalls = map[string]int64{}
+		for i, endpoint := range request.Endpoints {
+			if maxAPICalls[i] > 0 {
+				twitterConfig.Budget.MaxAPICalls[endpoint] = maxAPICalls[i]
+			}
+		}
+
+		twitterConfig.SearchUser = ""
+		twitterConfig.Seeds = mapValues(seedsMainMap)
+		if _, err := pipeline.ParseSeeds(twitterConfig.Seeds); err != nil {
+			logger.Error(err)
+		}
+
 		config.SetConfiguration(twitterConfig)
 		err := config.SaveConfiguration("")
 		if err != nil {
@@ -1,28 +1,166 @@
 package frontend
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"twfinder/config"
 	"twfinder/finder"
 	"twfinder/gui/server"
+	"twfinder/logger"
 	"twfinder/pipeline"
 	"twfinder/request"
 )
 
-func buildPipeline() {
+// buildPipeline : build new pipeline with the current configuration
+func buildPipeline() (*pipeline.Pipeline, error) {
 	/* finder build start */
-	finder.BuildSearchCriteria()
+	f, err := finder.NewFinder(config.Configuration().SearchCriteria)
+	if err != nil {
+		return nil, err
+	}
 	/* finder build end */
 
+	seeds, err := pipeline.Seeds(config.Configuration())
+	if err != nil {
+		return nil, err
+	}
+
 	/* build TwitterAPI start */
 	request.TwitterAPI()
 	/* build TwitterAPI end */
+	return pipeline.NewPipeline(f, seeds), nil
+}
+
+var (
+	// the running pipeline, it is rebuilt on every start to use the latest configuration
+	pip *pipeline.Pipeline
+	// lastPip : the running or the last pipeline for the stats
+	lastPip *pipeline.Pipeline
+	// lastStopReason : the reason the last pipeline has been stopped
+	lastStopReason string
+	pipMtx         sync.Mutex
+)
+
+// StopPipeline : stop the running pipeline if any and wait until its results and cache are stored,
+// it returns the reason the last pipeline has been stopped.
+func StopPipeline() string {
+	pipMtx.Lock()
+	defer pipMtx.Unlock()
+	if pip != nil {
+		<-pip.Stop()
+		lastStopReason = pip.StopReason()
+		pip = nil
+	}
+	return lastStopReason
+}
+
+// errNoPipeline : pause/resume without running pipeline
+var errNoPipeline = errors.New("no running pipeline")
+
+// PausePipeline : pause the running pipeline, it returns if the pipeline is paused
+func PausePipeline() (bool, error) {
+	pipMtx.Lock()
+	defer pipMtx.Unlock()
+	if pip == nil {
+		return false, errNoPipeline
+	}
+	pip.Pause()
+	return pip.Paused(), nil
+}
+
+// ResumePipeline : resume the paused pipeline, it returns if the pipeline is paused
+func ResumePipeline() (bool, error) {
+	pipMtx.Lock()
+	defer pipMtx.Unlock()
+	if pip == nil {
+		return false, errNoPipeline
+	}
+	pip.Resume()
+	return pip.Paused(), nil
+}
+
+// TogglePausePipeline : pause the running pipeline or resume it if it is paused
+func TogglePausePipeline() (bool, error) {
+	pipMtx.Lock()
+	defer pipMtx.Unlock()
+	if pip == nil {
+		return false, errNoPipeline
+	}
+	if !pip.Pause() {
+		pip.Resume()
+	}
+	return pip.Paused(), nil
+}
+
+// PipelineStats : the stats of the running pipeline or the last one
+func PipelineStats() (pipeline.Stats, error) {
+	pipMtx.Lock()
+	p := lastPip
+	pipMtx.Unlock()
+	if p == nil {
+		return pipeline.Stats{}, errNoPipeline
+	}
+	return p.Stats(), nil
+}
+
+// statsHTML : the stats as HTML table
+func statsHTML(st pipeline.Stats) string {
+	var b strings.Builder
+	row := func(name string, value interface{}) {
+		fmt.Fprintf(&b, "<tr><td><b>%v</b></td><td>%v</td></tr>", html.EscapeString(name), html.EscapeString(fmt.Sprint(value)))
+	}
+	b.WriteString("<table>")
+	row("Elapsed", st.Elapsed)
+	row("Paused", st.Paused)
+	if st.StopReason != "" {
+		row("Stop Reason", st.StopReason)
+	}
+	row("Discovered", st.Discovered)
+	row("Looked Up", st.LookedUp)
+	row("Matches", st.Matches)
+	row("Stored", st.Stored)
+	row("Frontier", st.Frontier)
+	row("Dropped", st.Dropped)
+	row("Skipped", st.Skipped)
+	row("Cache Hits", st.CacheHits)
+	for _, stage := range st.Stages {
+		row(fmt.Sprintf("Stage %v", stage.Stage), fmt.Sprintf("%v (%.1f/min)", stage.Processed, stage.PerMinute))
+	}
+	endpoints := make([]string, 0, len(st.Endpoints))
+	for endpoint := range st.Endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	for _, endpoint := range endpoints {
+		ep := st.Endpoints[endpoint]
+		row(endpoint, fmt.Sprintf("%v calls, %v rate limit waits, %v errors", ep.Calls, ep.RateLimitWaits, ep.Errors))
+	}
+	if st.RateLimitETA != "" {
+		row("Next Rate Limit Window", st.RateLimitETA)
+	}
+	b.WriteString("</table>")
+	return b.String()
+}
+
+// watchPipeline : forget the pipeline when it stops itself e.g. a budget limit is reached
+func watchPipeline(p *pipeline.Pipeline) {
+	<-p.Done()
+	pipMtx.Lock()
+	defer pipMtx.Unlock()
+	if pip == p {
+		lastStopReason = p.StopReason()
+		pip = nil
+	}
 }
 
 // HomeWin :
 func HomeWin() server.Window {
-	/* start Pipline */
-	pip := pipeline.NewPipeline()
-	/* start Pipline */
-
 	// Create and build a window
 	win := server.NewWindow("home", "Home - Twitter Finder App")
 	win.Style().SetFullWidth()
@@ -38,12 +176,34 @@ func HomeWin() server.Window {
 
 	startBtn := server.NewButton("Start")
 	startBtn.AddEHandlerFunc(func(e server.Event) {
-		lblTitle.SetText("Collecting Data ... ")
 		win.Add(lblTitle)
-		win.Add(lodImg)
 		//
-		buildPipeline()
-		pip.Start()
+		pipMtx.Lock()
+		defer pipMtx.Unlock()
+		if pip != nil {
+			lblTitle.SetText("Collecting Data ... (stop it to apply new configuration)")
+			e.MarkDirty(win)
+			return
+		}
+		var err error
+		pip, err = buildPipeline()
+		if err != nil {
+			logger.Error(err)
+			lblTitle.SetText(err.Error())
+			e.MarkDirty(win)
+			return
+		}
+		if err = pip.Start(context.Background()); err != nil {
+			pip = nil
+			logger.Error(err)
+			lblTitle.SetText(err.Error())
+			e.MarkDirty(win)
+			return
+		}
+		lastPip = pip
+		go watchPipeline(pip)
+		lblTitle.SetText("Collecting Data ... ")
+		win.Add(lodImg)
 		//
 		e.MarkDirty(win)
 	}, server.ETypeClick)
@@ -51,13 +211,57 @@ func HomeWin() server.Window {
 
 	stopBtn := server.NewButton("stop")
 	stopBtn.AddEHandlerFunc(func(e server.Event) {
-		lblTitle.SetText("Stop collection data !")
 		win.Remove(lodImg)
-		//
-		pip.Close()
+		// wait until the in-flight users are checked and stored
+		reason := StopPipeline()
+		lblTitle.SetText("Stop collection data !")
+		if reason != "" {
+			lblTitle.SetText(fmt.Sprintf("Stop collection data ! (%v)", reason))
+		}
 		//
 		e.MarkDirty(win)
 	}, server.ETypeClick)
 	win.Add(stopBtn)
+
+	pauseBtn := server.NewButton("Pause")
+	pauseBtn.AddEHandlerFunc(func(e server.Event) {
+		win.Add(lblTitle)
+		if _, err := PausePipeline(); err != nil {
+			lblTitle.SetText(err.Error())
+		} else {
+			win.Remove(lodImg)
+			lblTitle.SetText("Paused (resume to continue from the same point)")
+		}
+		e.MarkDirty(win)
+	}, server.ETypeClick)
+	win.Add(pauseBtn)
+
+	resumeBtn := server.NewButton("Resume")
+	resumeBtn.AddEHandlerFunc(func(e server.Event) {
+		win.Add(lblTitle)
+		if _, err := ResumePipeline(); err != nil {
+			lblTitle.SetText(err.Error())
+		} else {
+			lblTitle.SetText("Collecting Data ... ")
+			win.Add(lodImg)
+		}
+		e.MarkDirty(win)
+	}, server.ETypeClick)
+	win.Add(resumeBtn)
+
+	// stats of the running pipeline, refreshed every 5 seconds while the window is open
+	statsHTMLComp := server.NewHTML("")
+	statsTimer := server.NewTimer(5 * time.Second)
+	statsTimer.SetRepeat(true)
+	statsTimer.AddEHandlerFunc(func(e server.Event) {
+		st, err := PipelineStats()
+		if err != nil {
+			return
+		}
+		statsHTMLComp.SetHTML(statsHTML(st))
+		e.MarkDirty(statsHTMLComp)
+	}, server.ETypeStateChange)
+	win.Add(statsTimer)
+	win.Add(statsHTMLComp)
 	return win
 }
@@ -2,13 +2,24 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"twfinder/config"
+	"twfinder/eval"
+	"twfinder/finder"
 	"twfinder/gui/frontend"
 	"twfinder/gui/server"
 	"twfinder/logger"
 )
 
 func main() {
+	// "twfinder eval" evaluates the search criteria offline
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		os.Exit(runEval(os.Args[2:]))
+	}
+
 	// read Command-Line Flags
 	configPath := flag.String("c", "config.json", "configuration file path")
 	flag.Parse()
@@ -23,6 +34,11 @@ func main() {
 	defer logger.Close()
 	/* logger initialize end */
 
+	// SIGINT/SIGTERM stop the running pipeline gracefully, a second signal exits immediately
+	go handleSignals()
+	// SIGUSR1 pauses the running pipeline or resumes it
+	go handlePauseSignals()
+
 	// Create and start a GUI server (omitting error check)
 	server := server.NewServer("", "localhost:8081")
 	server.SetText("Twitter Finder App")
@@ -30,5 +46,73 @@ func main() {
 	server.AddWin(frontend.ConfigWin())
 	server.AddWin(frontend.FinderWin())
 	server.SetDefaultRootWindow(frontend.HomeWin())
+	frontend.RegisterAPI()
 	server.Start("home") // Also opens windows list in browser
 }
+
+// handleSignals : stop the pipeline with the same path as the stop button before exit
+func handleSignals() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigs
+	logger.Infof("[%v] stopping the pipeline, send it again to exit immediately", sig)
+	go func() {
+		<-sigs
+		logger.Close()
+		os.Exit(1)
+	}()
+	frontend.StopPipeline()
+	logger.Close()
+	os.Exit(0)
+}
+
+// handlePauseSignals : toggle pause/resume of the running pipeline on every signal
+func handlePauseSignals() {
+	if len(pauseSignals) == 0 {
+		return
+	}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, pauseSignals...)
+	for sig := range sigs {
+		paused, err := frontend.TogglePausePipeline()
+		if err != nil {
+			logger.Warnf("[%v] %v", sig, err)
+			continue
+		}
+		logger.Infof("[%v] pipeline paused: %v", sig, paused)
+	}
+}
+
+// runEval : run the SEARCH_CRITERIA over the profiles of a JSONL file without the twitter API
+// e.g. twfinder eval -c config.json -i profiles.jsonl
+func runEval(args []string) int {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	configPath := fs.String("c", "config.json", "configuration file path")
+	inputPath := fs.String("i", "", "profiles file path, one user JSON object per line")
+	fs.Parse(args)
+	if *inputPath == "" && fs.NArg() > 0 {
+		*inputPath = fs.Arg(0)
+	}
+	if *inputPath == "" {
+		fmt.Fprintln(os.Stderr, "usage: twfinder eval [-c config.json] -i profiles.jsonl")
+		return 2
+	}
+
+	config.BuildConfiguration(*configPath)
+	mylogger := logger.NewZapLogger()
+	logger.InitializeLogger(&mylogger)
+	defer logger.Close()
+
+	f, err := finder.NewFinder(config.Configuration().SearchCriteria)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error occurred during build the search criteria: %v\n", err)
+		return 1
+	}
+	sum, err := eval.EvaluateFile(f, *inputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error occurred during evaluate %v: %v\n", *inputPath, err)
+		return 1
+	}
+	sum.Print(os.Stdout)
+	return 0
+}
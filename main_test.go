@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"twfinder/config"
+	"twfinder/testutil"
+)
+
+func TestRunEval(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// runEval writes the logs to the working directory
+	testutil.ChdirTemp(t)
+	input := filepath.Join(wd, "eval", "testdata", "profiles.jsonl")
+
+	c := config.Config{SearchCriteria: config.SearchCriteria{SearchBioContext: []string{"golang"}}}
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("config.json", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"input flag", []string{"-c", "config.json", "-i", input}, 0},
+		{"positional input", []string{"-c", "config.json", input}, 0},
+		{"missing input", []string{"-c", "config.json"}, 2},
+		{"unknown input", []string{"-c", "config.json", "-i", "missing.jsonl"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := runEval(tt.args); code != tt.code {
+				t.Errorf("exit code %v, expected %v", code, tt.code)
+			}
+		})
+	}
+}
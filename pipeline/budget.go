@@ -0,0 +1,151 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"twfinder/config"
+	"twfinder/logger"
+	"twfinder/request"
+	"twfinder/static"
+	"twfinder/storage"
+)
+
+const runfile = "run.json"
+
+// stop reasons other than the budget limits
+const (
+	stopReasonStopped = "stopped"
+)
+
+// budget : the limits of the run, the first limit reached stops the pipeline
+type budget struct {
+	limits config.Budget
+	// stop : stop the pipeline, called once with the first reason
+	stop func()
+
+	mtx      sync.Mutex
+	started  time.Time
+	matches  int64
+	profiles int64
+	reason   string
+}
+
+// checkBudget : validate the limits of the run with the frontier order,
+// MAX_DEPTH_REACHED needs the FIFO order where the users are investigated by depth.
+func checkBudget(limits config.Budget, order string) error {
+	if limits.MaxDepthReached > 0 && order != "" && !strings.EqualFold(order, storage.FrontierFIFO) {
+		return fmt.Errorf("MAX_DEPTH_REACHED is available with FRONTIER_ORDER %v only, not %v", storage.FrontierFIFO, order)
+	}
+	return nil
+}
+
+func newBudget(limits config.Budget, stop func()) *budget {
+	request.SetAPICallLimits(limits.MaxAPICalls)
+	return &budget{limits: limits, stop: stop, started: time.Now()}
+}
+
+// exhausted : stop the pipeline with the reason, only the first reason is kept
+func (b *budget) exhausted(reason string) {
+	b.mtx.Lock()
+	first := b.reason == ""
+	if first {
+		b.reason = reason
+	}
+	b.mtx.Unlock()
+	if first {
+		if reason != stopReasonStopped {
+			logger.Warnf("[Budget] %v, stopping the pipeline", reason)
+		}
+		b.stop()
+	}
+}
+
+// stopReason : the reason the pipeline has been stopped, empty if it is running
+func (b *budget) stopReason() string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.reason
+}
+
+// counts : the matched users and the looked up users profiles
+func (b *budget) counts() (int64, int64) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.matches, b.profiles
+}
+
+// profile : count a looked up user profile
+func (b *budget) profile() {
+	b.mtx.Lock()
+	b.profiles++
+	reached := b.limits.MaxProfiles > 0 && b.profiles >= b.limits.MaxProfiles
+	b.mtx.Unlock()
+	if reached {
+		b.exhausted(fmt.Sprintf("max profiles %v reached", b.limits.MaxProfiles))
+	}
+}
+
+// match : count a matched user
+func (b *budget) match() {
+	b.mtx.Lock()
+	b.matches++
+	reached := b.limits.MaxMatches > 0 && b.matches >= b.limits.MaxMatches
+	b.mtx.Unlock()
+	if reached {
+		b.exhausted(fmt.Sprintf("max matches %v reached", b.limits.MaxMatches))
+	}
+}
+
+// depth : check the depth of the user about to be investigated, false if the limit is reached,
+// with the FIFO frontier the users of the lower depths have been investigated already.
+func (b *budget) depth(depth int64) bool {
+	if b.limits.MaxDepthReached > 0 && depth >= b.limits.MaxDepthReached {
+		b.exhausted(fmt.Sprintf("max depth %v reached", b.limits.MaxDepthReached))
+		return false
+	}
+	return true
+}
+
+// timer : stop the pipeline when the max duration passes, the returned timer should be stopped with the pipeline
+func (b *budget) timer() *time.Timer {
+	if b.limits.MaxDurationMinutes <= 0 {
+		return nil
+	}
+	d := time.Duration(b.limits.MaxDurationMinutes) * time.Minute
+	return time.AfterFunc(d, func() {
+		b.exhausted(fmt.Sprintf("max duration %v reached", d))
+	})
+}
+
+// run : the record of the run, saved with the results
+type run struct {
+	Started    time.Time        `json:"STARTED"`
+	Stopped    time.Time        `json:"STOPPED"`
+	StopReason string           `json:"STOP_REASON"`
+	Matches    int64            `json:"MATCHES"`
+	Profiles   int64            `json:"PROFILES"`
+	APICalls   map[string]int64 `json:"API_CALLS"`
+}
+
+// save : save the record of the run with the results
+func (b *budget) save() error {
+	b.mtx.Lock()
+	r := run{
+		Started:    b.started,
+		Stopped:    time.Now(),
+		StopReason: b.reason,
+		Matches:    b.matches,
+		Profiles:   b.profiles,
+		APICalls:   request.APICalls(),
+	}
+	b.mtx.Unlock()
+	data, err := json.MarshalIndent(r, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fmt.Sprintf("%v/%v", static.STORAGEDIR, runfile), data, 0644)
+}
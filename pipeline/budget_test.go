@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"twfinder/config"
+	"twfinder/static"
+	"twfinder/testutil"
+)
+
+// stopCounter : count the calls of the budget stop
+type stopCounter struct{ calls int }
+
+func (s *stopCounter) stop() { s.calls++ }
+
+func TestCheckBudgetDepthOrder(t *testing.T) {
+	tests := []struct {
+		order string
+		ok    bool
+	}{
+		{"", true},
+		{"FIFO", true},
+		{"fifo", true},
+		{"LIFO", false},
+		{"SCORE", false},
+		{"FOLLOWERS", false},
+	}
+	for _, tt := range tests {
+		err := checkBudget(config.Budget{MaxDepthReached: 2}, tt.order)
+		if (err == nil) != tt.ok {
+			t.Errorf("MAX_DEPTH_REACHED with %q: error %v, expected ok %v", tt.order, err, tt.ok)
+		}
+		// the other limits are available with every order
+		if err := checkBudget(config.Budget{MaxMatches: 10}, tt.order); err != nil {
+			t.Errorf("MAX_MATCHES with %q: %v", tt.order, err)
+		}
+	}
+}
+
+func TestStartRejectsDepthBudgetWithoutFIFO(t *testing.T) {
+	testutil.InitLogger()
+	testutil.ChdirTemp(t)
+	old := config.Configuration()
+	t.Cleanup(func() { config.SetConfiguration(old) })
+	config.SetConfiguration(config.Config{FrontierOrder: "LIFO", Budget: config.Budget{MaxDepthReached: 2}})
+
+	p := NewPipeline(nil, nil)
+	if err := p.Start(context.Background()); err == nil {
+		t.Fatal("started with MAX_DEPTH_REACHED and LIFO frontier")
+	}
+	waitClosed(t, p.Done(), "the done channel")
+}
+
+func TestBudgetLimits(t *testing.T) {
+	testutil.InitLogger()
+	tests := []struct {
+		name   string
+		limits config.Budget
+		count  func(b *budget)
+		// counts : the counts that reach the limit
+		counts int
+		reason string
+	}{
+		{"profiles", config.Budget{MaxProfiles: 3}, (*budget).profile, 3, "max profiles 3 reached"},
+		{"matches", config.Budget{MaxMatches: 2}, (*budget).match, 2, "max matches 2 reached"},
+	}
+	for _, tt := range tests {
+		s := &stopCounter{}
+		b := newBudget(tt.limits, s.stop)
+		n := 0
+		for n < 10 && b.stopReason() == "" {
+			tt.count(b)
+			n++
+		}
+		if n != tt.counts {
+			t.Errorf("%v: stopped after %v counts, expected %v", tt.name, n, tt.counts)
+		}
+		if b.stopReason() != tt.reason || s.calls != 1 {
+			t.Errorf("%v: reason %q after %v stops, expected %q once", tt.name, b.stopReason(), s.calls, tt.reason)
+		}
+	}
+
+	s := &stopCounter{}
+	b := newBudget(config.Budget{MaxDepthReached: 3}, s.stop)
+	for depth := int64(0); depth < 3; depth++ {
+		if !b.depth(depth) {
+			t.Errorf("depth %v stopped the run, expected MAX_DEPTH_REACHED 3", depth)
+		}
+	}
+	if b.depth(3) || b.stopReason() != "max depth 3 reached" || s.calls != 1 {
+		t.Errorf("depth 3: reason %q after %v stops, expected max depth 3 reached", b.stopReason(), s.calls)
+	}
+
+	// the first reason is kept
+	b.exhausted(stopReasonStopped)
+	b.match()
+	if b.stopReason() != "max depth 3 reached" || s.calls != 1 {
+		t.Errorf("reason %q after %v stops, expected the first reason once", b.stopReason(), s.calls)
+	}
+
+	// no limits
+	s = &stopCounter{}
+	b = newBudget(config.Budget{}, s.stop)
+	for i := 0; i < 100; i++ {
+		b.profile()
+		b.match()
+		b.depth(int64(i))
+	}
+	if b.stopReason() != "" || s.calls != 0 || b.timer() != nil {
+		t.Errorf("reason %q after %v stops without limits", b.stopReason(), s.calls)
+	}
+}
+
+func TestBudgetSaveRun(t *testing.T) {
+	testutil.InitLogger()
+	testutil.ChdirTemp(t)
+	if err := os.MkdirAll(static.STORAGEDIR, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	s := &stopCounter{}
+	b := newBudget(config.Budget{MaxMatches: 2}, s.stop)
+	for i := 0; i < 5; i++ {
+		b.profile()
+	}
+	b.match()
+	b.match()
+	if err := b.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(fmt.Sprintf("%v/%v", static.STORAGEDIR, runfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r run
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.StopReason != "max matches 2 reached" || r.Matches != 2 || r.Profiles != 5 {
+		t.Errorf("run %+v, expected max matches 2 reached, 2 matches and 5 profiles", r)
+	}
+	if r.Started.IsZero() || r.Stopped.Before(r.Started) {
+		t.Errorf("run started %v stopped %v", r.Started, r.Stopped)
+	}
+	if len(r.APICalls) != 0 {
+		t.Errorf("API calls %v, expected none", r.APICalls)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"STARTED", "STOPPED", "STOP_REASON", "MATCHES", "PROFILES", "API_CALLS"} {
+		if _, ok := fields[f]; !ok {
+			t.Errorf("%v is not recorded in %v: %s", f, runfile, data)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package pipeline
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 	"twfinder/config"
 	"twfinder/finder"
@@ -19,113 +22,491 @@ import (
 // Pipeline :
 type Pipeline struct {
 	InputUserIdsChn chan int64
-	userInvstChn    chan int64
-	userDetailsChn  chan anaconda.User
-	validUserChn    chan anaconda.User
+	userDetailsChn  chan finder.Profile
+	validUserChn    chan storage.Result
+	// frontier : the users under investigation
+	frontier *storage.Frontier
+
+	finder *finder.Finder
+	// seeds : the users to start with
+	seeds []Seed
+	// cancel : stop collecting new users, the in-flight users are drained
+	cancel context.CancelFunc
+	// budget : the limits of the run and the reason it has been stopped
+	budget *budget
+	// gate : the stages are idle while the pipeline is paused
+	gate pauseGate
+	// progress : the in-flight state, recovered the checkpoint of the last run
+	progress  progress
+	recovered checkpoint
+	// stateMtx : the cache, the frontier and the checkpoint are saved by one goroutine at a time
+	stateMtx sync.Mutex
+	stopped  bool
+	// counters : the users counted by the stages for the stats
+	counters counters
+	// followers : the user followers/following ids API from the cursor
+	followers func(ctx context.Context, username string, userID int64, from request.Cursor, found func([]int64, request.Cursor) error) error
+	// lookup : the users lookup API, flushInterval the max wait of a partial lookup patch
+	lookup        func(ids []int64) ([]finder.Profile, error)
+	flushInterval time.Duration
+	// apiStats : the API calls stats per endpoint
+	apiStats func() map[string]request.EndpointStats
+	// done : closed when the pipeline has been stopped, or by Stop and Start if it has not been started
+	done     chan struct{}
+	doneOnce sync.Once
 }
 
-// NewPipeline :
-func NewPipeline() *Pipeline {
+// NewPipeline : f the finder to check the users with, seeds the users to start with
+func NewPipeline(f *finder.Finder, seeds []Seed) *Pipeline {
 	return &Pipeline{
+		finder:          f,
+		seeds:           seeds,
 		InputUserIdsChn: make(chan int64),
-		userInvstChn:    make(chan int64, 1000),
-		userDetailsChn:  make(chan anaconda.User),
-		validUserChn:    make(chan anaconda.User),
+		userDetailsChn:  make(chan finder.Profile),
+		validUserChn:    make(chan storage.Result),
+		done:            make(chan struct{}),
+		followers:       request.UserFollowersFollowing,
+		lookup:          request.GetUsersLookup,
+		apiStats:        request.APIStats,
+		flushInterval:   static.TWITTERPATCHTIMEOUT,
 	}
 }
 
-// Start :
-func (p *Pipeline) Start() {
+// Start : start the pipeline stages, the pipeline is stopped when ctx is canceled or Stop is called.
+// the stages are stopped in order, every stage closes its output channel when its input is drained:
+// seeds -> followers/following -> users lookup -> validation -> store -> last cache update.
+func (p *Pipeline) Start(ctx context.Context) error {
+	select {
+	case <-p.done:
+		return errors.New("the pipeline has been stopped")
+	default:
+	}
+	if err := checkBudget(config.Configuration().Budget, config.Configuration().FrontierOrder); err != nil {
+		p.closeDone()
+		return err
+	}
 	p.prepareStorage()
 	// load the cache if exist
-	storage.LoadCache(p.userInvstChn)
+	storage.LoadCache()
+	frontier, err := storage.NewFrontier(static.STORAGEDIR, config.Configuration().FrontierOrder)
+	if err != nil {
+		p.closeDone()
+		return err
+	}
+	p.frontier = frontier
+	if cp, ok := loadCheckpoint(); ok {
+		// the last run has not been stopped cleanly
+		p.recovered = cp
+		if cp.Investigating != 0 {
+			p.frontier.AddInvestUser(storage.InvestUser{ID: cp.Investigating})
+		}
+		logger.Infof("[Checkpoint] continue the last run, user %v from %v, %v pending users", cp.Investigating, cp.Cursor, len(cp.Pending))
+	}
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.budget = newBudget(config.Configuration().Budget, p.cancel)
+	timer := p.budget.timer()
+	// seeding : the seeds are pushed to the frontier before it is closed
+	var seeding sync.WaitGroup
+	seeding.Add(1)
+	go func() {
+		defer seeding.Done()
+		p.pushSeeds(ctx)
+	}()
 
-	go p.getUsersDetailsBatches()
+	go func() {
+		p.getUserFollowersFollowing(ctx)
+		close(p.InputUserIdsChn)
+	}()
+
+	go func() {
+		p.getUsersDetailsBatches(ctx)
+		close(p.userDetailsChn)
+	}()
+
+	go func() {
+		p.checkValidateUser(ctx)
+		close(p.validUserChn)
+	}()
+
+	cacheDone := make(chan struct{})
+	go func() {
+		p.updateCache(ctx)
+		close(cacheDone)
+	}()
+
+	go func() {
+		p.storeResult()
+		<-cacheDone
+		seeding.Wait()
+		p.stateMtx.Lock()
+		p.stopped = true
+		if err := storage.UpdateCache(); err == nil {
+			logger.Info("cache has been updated")
+		}
+		if err := p.frontier.Close(); err != nil {
+			logger.Error(err)
+		}
+		if err := finalCheckpoint(p.progress.deferredIds()); err != nil {
+			logger.Error(err)
+		}
+		p.stateMtx.Unlock()
+		if timer != nil {
+			timer.Stop()
+		}
+		// stopped by the parent context
+		p.budget.exhausted(stopReasonStopped)
+		if err := p.budget.save(); err != nil {
+			logger.Error(err)
+		}
+		logger.Infof("[Stop] pipeline has been stopped (%v)", p.budget.stopReason())
+		p.closeDone()
+	}()
+	return nil
+}
 
-	go p.getUserFollowersFollowing()
+// Stop : stop collecting new users and drain the in-flight users,
+// the returned channel is closed when the results and the cache are stored,
+// or right away if the pipeline has not been started.
+func (p *Pipeline) Stop() <-chan struct{} {
+	if p.budget == nil {
+		p.closeDone()
+		return p.done
+	}
+	p.budget.exhausted(stopReasonStopped)
+	return p.done
+}
 
-	go p.checkValidateUser()
+// closeDone : close done once
+func (p *Pipeline) closeDone() {
+	p.doneOnce.Do(func() { close(p.done) })
+}
 
-	go p.storeResult()
+// Pause : make every stage idle, the in-flight users and the pagination cursor are kept
+// and checkpointed with the cache, false if it is not running or already paused.
+func (p *Pipeline) Pause() bool {
+	if p.budget == nil || p.budget.stopReason() != "" || !p.gate.pause() {
+		return false
+	}
+	logger.Info("[Pause] pipeline has been paused")
+	p.saveState()
+	return true
+}
 
-	go p.updateCache()
+// Resume : continue the paused stages from the same point, false if it is not paused
+func (p *Pipeline) Resume() bool {
+	if !p.gate.resume() {
+		return false
+	}
+	logger.Info("[Resume] pipeline has been resumed")
+	return true
 }
 
-func (p *Pipeline) getUsersDetailsBatches() {
+// Paused : check if the pipeline is paused
+func (p *Pipeline) Paused() bool {
+	return p.gate.paused()
+}
+
+// StopReason : the budget limit that stopped the pipeline or "stopped", empty if it is running
+func (p *Pipeline) StopReason() string {
+	if p.budget == nil {
+		return ""
+	}
+	return p.budget.stopReason()
+}
+
+// Done : closed when the pipeline has been stopped
+func (p *Pipeline) Done() <-chan struct{} {
+	return p.done
+}
+
+// getUsersDetailsBatches : lookup the new users in patches until InputUserIdsChn is closed,
+// a partial patch is looked up when its first user waited flushInterval and before it returns.
+func (p *Pipeline) getUsersDetailsBatches(ctx context.Context) {
+	// the pending users of the last run checkpoint, they are already in the cache
+	pending := p.recovered.Pending
+	for len(pending) > 0 {
+		n := static.TWITTERPATCHSIZE
+		if n > len(pending) {
+			n = len(pending)
+		}
+		p.lookupBatch(ctx, pending[:n])
+		pending = pending[n:]
+	}
+
+	inIdes := make([]int64, 0, static.TWITTERPATCHSIZE)
+	// flush : nil while the patch is empty
+	var flush <-chan time.Time
 	for {
-		inIdes := make([]int64, static.TWITTERPATCHSIZE)
-		for i := 0; i < static.TWITTERPATCHSIZE; i++ {
-			id := <-p.InputUserIdsChn
+		select {
+		case id, ok := <-p.InputUserIdsChn:
+			if !ok {
+				if len(inIdes) > 0 {
+					p.lookupBatch(ctx, inIdes)
+				}
+				return
+			}
 			if storage.CheckOldUser(id) {
-				i--
+				p.counters.add(&p.counters.cacheHits, 1)
 				continue
 			}
-			inIdes[i] = id
-		}
-
-		if len(inIdes) > 0 {
-			res, err := request.GetUsersLookup(inIdes)
-			if err != nil {
-				logger.Error(err)
+			inIdes = append(inIdes, id)
+			p.progress.setBatch(inIdes, nil)
+			if len(inIdes) == 1 {
+				flush = time.After(p.flushInterval)
 			}
-			for _, u := range res {
-				p.userDetailsChn <- u
+			if len(inIdes) < static.TWITTERPATCHSIZE {
+				continue
 			}
+		case <-flush:
 		}
+		p.lookupBatch(ctx, inIdes)
+		inIdes = make([]int64, 0, static.TWITTERPATCHSIZE)
+		flush = nil
 	}
 }
 
-func (p *Pipeline) getUserFollowersFollowing() {
-	c := config.Configuration()
-	// First User
-	err := request.UserFollowersFollowing(c.SearchUser, 0, p.InputUserIdsChn)
+// lookupBatch : lookup the batch when the pipeline is not paused,
+// the ids are kept in the progress until the users are sent to the validation.
+func (p *Pipeline) lookupBatch(ctx context.Context, ids []int64) {
+	p.progress.setBatch(nil, ids)
+	// a stopping pipeline is drained even if it is paused
+	p.gate.wait(ctx)
+	p.lookupUsers(ctx, ids)
+	p.progress.setBatch(nil, nil)
+}
+
+// lookupUsers : get the users details, in case of rate limit it waits for the next window
+// unless the pipeline is stopping, then the ids are kept in the checkpoint for the next run.
+func (p *Pipeline) lookupUsers(ctx context.Context, ids []int64) {
+	res, err := p.lookup(ids)
+	if isRateLimit(err) {
+		if !waitRateLimit(ctx, err) {
+			p.deferLookup(ids)
+			return
+		}
+		res, err = p.lookup(ids)
+	}
+	if p.budgetExhausted(err) {
+		p.deferLookup(ids)
+		return
+	}
 	if err != nil {
 		logger.Error(err)
 	}
+	p.counters.add(&p.counters.lookedUp, len(res))
+	// not found, suspended or failed
+	p.counters.add(&p.counters.dropped, len(ids)-len(res))
+	for i, u := range res {
+		p.userDetailsChn <- u
+		p.progress.setBatch(nil, userIds(res[i+1:]))
+	}
+}
+
+// deferLookup : the ids are marked as checked in the cache, they are looked up by the next run
+func (p *Pipeline) deferLookup(ids []int64) {
+	logger.Warnf("[Stop] %v users have not been looked up, they are kept for the next run", len(ids))
+	p.progress.deferIds(ids)
+}
 
+// isRateLimit : check if err is rate limit error of the twitter API
+func isRateLimit(err error) bool {
+	if aerr, ok := err.(*anaconda.ApiError); ok {
+		isRateLimitError, _ := aerr.RateLimitCheck()
+		return isRateLimitError
+	}
+	return false
+}
+
+// waitRateLimit : wait for the next window if err is rate limit error,
+// false if it is not or ctx is canceled before the window.
+func waitRateLimit(ctx context.Context, err error) bool {
+	aerr, ok := err.(*anaconda.ApiError)
+	if !ok {
+		return false
+	}
+	isRateLimitError, nextWindow := aerr.RateLimitCheck()
+	if !isRateLimitError {
+		return false
+	}
+	logger.Errorf("Rate limit exceeded Error, The application will try again after %v", nextWindow)
+	select {
+	case <-time.After(time.Until(nextWindow)):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// getUserFollowersFollowing : collect the ids of the users under investigation until ctx is canceled,
+// a user that is interrupted is kept under investigation for the next run,
+// a rate limited user is investigated again from the cursor it has reached.
+func (p *Pipeline) getUserFollowersFollowing(ctx context.Context) {
+	c := config.Configuration()
+	// resume : the cursor of the users that have been interrupted
+	resume := map[int64]request.Cursor{}
+	if p.recovered.Investigating != 0 {
+		resume[p.recovered.Investigating] = p.recovered.Cursor
+	}
 	for {
-		userID := <-p.userInvstChn
-		storage.RemoveInvestUser(userID)
-		logger.Infof("[New User] %v", userID)
-		err := request.UserFollowersFollowing("", userID, p.InputUserIdsChn)
+		p.progress.setCursor(0, request.Cursor{})
+		if err := p.gate.wait(ctx); err != nil {
+			return
+		}
+		u, err := p.frontier.RemoveInvestUser(ctx)
 		if err != nil {
-			if aerr, ok := err.(*anaconda.ApiError); ok {
-				if isRateLimitError, nextWindow := aerr.RateLimitCheck(); isRateLimitError {
-					logger.Errorf("Rate limit exceeded Error, The application will try again after %v", nextWindow)
-					<-time.After(time.Until(nextWindow))
+			return
+		}
+		userID := u.ID
+		depth, seed := userDepth(userID), storage.UserSeed(userID)
+		if !expandDepth(c, depth) {
+			// the cache of a previous run with higher MAX_DEPTH
+			logger.Infof("[Skip User] %v depth %v, MAX_DEPTH %v", userID, depth, c.MaxDepth)
+			p.counters.add(&p.counters.skipped, 1)
+			continue
+		}
+		if !p.budget.depth(depth) {
+			p.frontier.AddInvestUser(u)
+			return
+		}
+		from := resume[userID]
+		delete(resume, userID)
+		p.progress.setCursor(userID, from)
+		logger.Infof("[New User] %v depth %v from %v", userID, depth, from)
+		err = p.followers(ctx, "", userID, from, p.sendIds(ctx, userID, depth+1, seed))
+		if errors.Is(err, context.Canceled) || p.budgetExhausted(err) {
+			p.frontier.AddInvestUser(u)
+			return
+		}
+		if err != nil {
+			if _, ok := err.(*anaconda.ApiError); ok {
+				if isRateLimit(err) {
+					// investigate the user again from the cursor reached after the rate limit window
+					_, resume[userID] = p.progress.investigatingCursor()
+					p.frontier.AddInvestUser(u)
+					if !waitRateLimit(ctx, err) {
+						return
+					}
 				}
 			} else {
 				logger.Errorf("%v\n>>> Error occurred during request user:%v", err, userID)
-				err = request.UserFollowersFollowing("", userID, p.InputUserIdsChn)
+				// try again from the last page
+				_, from = p.progress.investigatingCursor()
+				err = p.followers(ctx, "", userID, from, p.sendIds(ctx, userID, depth+1, seed))
+				if errors.Is(err, context.Canceled) || p.budgetExhausted(err) {
+					p.frontier.AddInvestUser(u)
+					return
+				}
 				if err != nil {
 					logger.Errorf("%v\n>>> [skip user] Error occurred during request user:<%v>", err, userID)
+					p.counters.add(&p.counters.skipped, 1)
 				}
 			}
 		}
 	}
 }
 
-func (p *Pipeline) checkValidateUser() {
+// pushSeeds : push the users of the seeds to the users under investigation at depth 0
+func (p *Pipeline) pushSeeds(ctx context.Context) {
+	err := resolveSeeds(ctx, p.seeds, func(s Seed, ids []int64) error {
+		logger.Infof("[Seed] %v %v users", s, len(ids))
+		for _, id := range ids {
+			storage.SetUserDepth(id, 0, s.String())
+			p.frontier.AddInvestUser(storage.InvestUser{ID: id})
+		}
+		p.counters.add(&p.counters.discovered, len(ids))
+		if err := p.gate.wait(ctx); err != nil {
+			return err
+		}
+		return ctx.Err()
+	})
+	if err != nil && !errors.Is(err, context.Canceled) && !p.budgetExhausted(err) {
+		logger.Error(err)
+	}
+}
+
+// budgetExhausted : stop the pipeline if err is the API calls limit of an endpoint
+func (p *Pipeline) budgetExhausted(err error) bool {
+	var berr *request.BudgetError
+	if errors.As(err, &berr) {
+		p.budget.exhausted(berr.Error())
+		return true
+	}
+	return false
+}
+
+// sendIds : send the ids of the user followers/following found at depth from the seed to InputUserIdsChn
+// until ctx is canceled, the next page is requested when the pipeline is not paused.
+func (p *Pipeline) sendIds(ctx context.Context, userID int64, depth int64, seed string) func([]int64, request.Cursor) error {
+	return func(ids []int64, next request.Cursor) error {
+		for _, id := range ids {
+			storage.SetUserDepth(id, depth, seed)
+			select {
+			case p.InputUserIdsChn <- id:
+				p.counters.add(&p.counters.discovered, 1)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		p.progress.setCursor(userID, next)
+		return p.gate.wait(ctx)
+	}
+}
+
+// userIds : the ids of the users
+func userIds(users []finder.Profile) []int64 {
+	ids := make([]int64, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.Id)
+	}
+	return ids
+}
+
+// userDepth : hops from the seed, users without depth (cache of older versions) are counted as 1
+func userDepth(id int64) int64 {
+	if d, ok := storage.UserDepth(id); ok {
+		return d
+	}
+	return 1
+}
+
+// expandDepth : check if the followers/following of the user at depth should be investigated
+func expandDepth(c config.Config, depth int64) bool {
+	return c.MaxDepth <= 0 || depth < c.MaxDepth
+}
+
+// checkValidateUser : check the users until userDetailsChn is closed,
+// the users to investigate are added to the frontier, also when the pipeline is stopping for the next run.
+func (p *Pipeline) checkValidateUser(ctx context.Context) {
 	c := config.Configuration()
 	for {
-		user := <-p.userDetailsChn
-		valid := finder.CheckUserCriteria(&user)
+		// a stopping pipeline is drained even if it is paused
+		p.gate.wait(ctx)
+		user, ok := <-p.userDetailsChn
+		if !ok {
+			return
+		}
+		p.budget.profile()
+		report := p.finder.MatchProfile(&user)
+		depth := userDepth(user.Id)
+		valid := report.Matched
 		if valid {
-			logger.Infof("[MATCH] (%v) https://twitter.com/%v", user.Id, user.ScreenName)
-			p.validUserChn <- user
+			report.Describe(&user.User)
+			seed := storage.UserSeed(user.Id)
+			logger.Infof("[MATCH] (%v) https://twitter.com/%v depth:%v seed:%v %v", user.Id, user.ScreenName, depth, seed, report)
+			p.validUserChn <- storage.Result{Profile: user, Report: report, Depth: depth, Seed: seed}
+			p.budget.match()
 		}
 
-		if (c.Recursive && c.RecursiveSuccessUsersOnly && valid) || (c.Recursive && !c.RecursiveSuccessUsersOnly) {
-			// to ignore in case the channel is full.
-			select {
-			case p.userInvstChn <- user.Id:
-				storage.AddInvestUser(user.Id)
-			default:
-			}
+		if !expandDepth(c, depth) {
+			continue
+		}
+		if (c.Recursive && c.RecursiveSuccessUsersOnly && p.finder.RecursiveMatch(report)) || (c.Recursive && !c.RecursiveSuccessUsersOnly) {
+			p.frontier.AddInvestUser(storage.InvestUser{ID: user.Id, Score: report.Score, Followers: user.FollowersCount})
 		}
 	}
 }
 
+// storeResult : store the valid users until validUserChn is closed
 func (p *Pipeline) storeResult() {
 	// html storage
 	htmlstor, err := html.BuildHTMLStore()
@@ -144,7 +525,7 @@ func (p *Pipeline) storeResult() {
 		}
 	}
 
-	storage.Store(p.validUserChn)
+	storage.Store(p.validUserChn, func(n int) { p.counters.add(&p.counters.stored, n) })
 }
 
 func (p *Pipeline) prepareStorage() {
@@ -161,18 +542,33 @@ func (p *Pipeline) prepareStorage() {
 	}
 }
 
-func (p *Pipeline) updateCache() {
+// updateCache : save the cache every minute until ctx is canceled
+func (p *Pipeline) updateCache(ctx context.Context) {
+	ticker := time.NewTicker(60 * time.Second)
+	defer ticker.Stop()
 	for {
-		time.Sleep(60 * time.Second)
-		storage.UpdateCache()
-		logger.Info("cache has been updated")
+		select {
+		case <-ticker.C:
+			p.saveState()
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
-// Close :
-func (p *Pipeline) Close() {
-	close(p.InputUserIdsChn)
-	close(p.userInvstChn)
-	close(p.userDetailsChn)
-	close(p.validUserChn)
+// saveState : save the cache, the frontier and the checkpoint of the in-flight users
+func (p *Pipeline) saveState() {
+	p.stateMtx.Lock()
+	defer p.stateMtx.Unlock()
+	if p.stopped {
+		return
+	}
+	storage.UpdateCache()
+	if err := p.frontier.Save(); err != nil {
+		logger.Error(err)
+	}
+	if err := saveCheckpoint(p.progress.checkpoint(p.gate.paused())); err != nil {
+		logger.Error(err)
+	}
+	logger.Info("cache has been updated")
 }
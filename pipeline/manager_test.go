@@ -0,0 +1,619 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"twfinder/config"
+	"twfinder/finder"
+	"twfinder/request"
+	"twfinder/static"
+	"twfinder/storage"
+	"twfinder/testutil"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// fakeLookup : users lookup API that records the requested patches
+type fakeLookup struct {
+	mtx     sync.Mutex
+	patches [][]int64
+	// called : signaled on every lookup
+	called chan struct{}
+	// err : returned by every lookup if set
+	err error
+}
+
+func newFakeLookup() *fakeLookup {
+	return &fakeLookup{called: make(chan struct{}, 100)}
+}
+
+func (f *fakeLookup) lookup(ids []int64) ([]finder.Profile, error) {
+	f.mtx.Lock()
+	f.patches = append(f.patches, append([]int64{}, ids...))
+	err := f.err
+	f.mtx.Unlock()
+	if err != nil {
+		f.called <- struct{}{}
+		return nil, err
+	}
+	users := make([]finder.Profile, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, finder.Profile{User: anaconda.User{Id: id}})
+	}
+	f.called <- struct{}{}
+	return users, nil
+}
+
+func (f *fakeLookup) lookedUp() [][]int64 {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return append([][]int64{}, f.patches...)
+}
+
+// loadCacheOnce : the cache is loaded once, LoadCache resets its locks
+var loadCacheOnce sync.Once
+
+// newBatchPipeline : pipeline with the batcher stage only, the looked up users are collected
+func newBatchPipeline(f *fakeLookup, flushInterval time.Duration) (*Pipeline, chan []int64) {
+	return newBatchPipelineFrom(f, flushInterval, checkpoint{})
+}
+
+// newBatchPipelineFrom : batcher stage that continues the checkpoint of the last run
+func newBatchPipelineFrom(f *fakeLookup, flushInterval time.Duration, cp checkpoint) (*Pipeline, chan []int64) {
+	loadCacheOnce.Do(func() {
+		testutil.InitLogger()
+		storage.LoadCache()
+	})
+
+	p := NewPipeline(nil, nil)
+	p.lookup = f.lookup
+	p.flushInterval = flushInterval
+	p.recovered = cp
+	p.budget = newBudget(config.Budget{}, func() {})
+
+	users := make(chan []int64, 1)
+	go func() {
+		ids := []int64{}
+		for u := range p.userDetailsChn {
+			ids = append(ids, u.Id)
+		}
+		users <- ids
+	}()
+	go func() {
+		p.getUsersDetailsBatches(context.Background())
+		close(p.userDetailsChn)
+	}()
+	return p, users
+}
+
+// lastTestID : the ids of every test are new, the checked users are cached for the whole test binary
+var lastTestID int64 = 1000
+
+// newTestIds : n new ids
+func newTestIds(n int) []int64 {
+	ids := make([]int64, 0, n)
+	for i := 0; i < n; i++ {
+		lastTestID++
+		ids = append(ids, lastTestID)
+	}
+	return ids
+}
+
+// sendTestIds : send n new ids
+func sendTestIds(p *Pipeline, n int) []int64 {
+	ids := newTestIds(n)
+	for _, id := range ids {
+		p.InputUserIdsChn <- id
+	}
+	return ids
+}
+
+// checkPatches : the patches are not empty, not bigger than TWITTERPATCHSIZE and without zero ids
+func checkPatches(t *testing.T, patches [][]int64) {
+	t.Helper()
+	for _, patch := range patches {
+		if len(patch) == 0 || len(patch) > static.TWITTERPATCHSIZE {
+			t.Errorf("patch of %v ids, expected 1 to %v", len(patch), static.TWITTERPATCHSIZE)
+		}
+		for _, id := range patch {
+			if id == 0 {
+				t.Errorf("zero id in patch %v", patch)
+			}
+		}
+	}
+}
+
+func TestBatchesEmptyInput(t *testing.T) {
+	f := newFakeLookup()
+	p, users := newBatchPipeline(f, time.Hour)
+	close(p.InputUserIdsChn)
+
+	if ids := <-users; len(ids) != 0 {
+		t.Errorf("looked up users %v, expected none", ids)
+	}
+	if patches := f.lookedUp(); len(patches) != 0 {
+		t.Errorf("lookup patches %v, expected none", patches)
+	}
+}
+
+func TestBatchesShortInputFlushedOnClose(t *testing.T) {
+	f := newFakeLookup()
+	p, users := newBatchPipeline(f, time.Hour)
+	sendTestIds(p, 5)
+	close(p.InputUserIdsChn)
+
+	if ids := <-users; len(ids) != 5 {
+		t.Errorf("looked up %v users, expected 5", len(ids))
+	}
+	patches := f.lookedUp()
+	checkPatches(t, patches)
+	if len(patches) != 1 || len(patches[0]) != 5 {
+		t.Errorf("lookup patches %v, expected one patch of 5 ids", patches)
+	}
+}
+
+func TestBatchesShortInputFlushedOnTimer(t *testing.T) {
+	f := newFakeLookup()
+	p, users := newBatchPipeline(f, 20*time.Millisecond)
+	sendTestIds(p, 3)
+
+	// the input is still open, the partial patch is flushed by the timer
+	select {
+	case <-f.called:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the partial patch has not been flushed")
+	}
+	patches := f.lookedUp()
+	checkPatches(t, patches)
+	if len(patches) != 1 || len(patches[0]) != 3 {
+		t.Errorf("lookup patches %v, expected one patch of 3 ids", patches)
+	}
+
+	sendTestIds(p, 2)
+	close(p.InputUserIdsChn)
+	if ids := <-users; len(ids) != 5 {
+		t.Errorf("looked up %v users, expected 5", len(ids))
+	}
+	checkPatches(t, f.lookedUp())
+}
+
+func TestBatchesFullAndPartialPatch(t *testing.T) {
+	f := newFakeLookup()
+	p, users := newBatchPipeline(f, time.Hour)
+	sendTestIds(p, static.TWITTERPATCHSIZE+1)
+	close(p.InputUserIdsChn)
+
+	if ids := <-users; len(ids) != static.TWITTERPATCHSIZE+1 {
+		t.Errorf("looked up %v users, expected %v", len(ids), static.TWITTERPATCHSIZE+1)
+	}
+	patches := f.lookedUp()
+	checkPatches(t, patches)
+	if len(patches) != 2 || len(patches[0]) != static.TWITTERPATCHSIZE || len(patches[1]) != 1 {
+		t.Errorf("lookup patches of %v, expected %v and 1 ids", patchSizes(patches), static.TWITTERPATCHSIZE)
+	}
+}
+
+func TestBatchesCachedUsersOnly(t *testing.T) {
+	f := newFakeLookup()
+	p, users := newBatchPipeline(f, 10*time.Millisecond)
+	ids := newTestIds(3)
+	for _, id := range ids {
+		storage.CheckOldUser(id)
+	}
+	for _, id := range ids {
+		p.InputUserIdsChn <- id
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(p.InputUserIdsChn)
+
+	if ids := <-users; len(ids) != 0 {
+		t.Errorf("looked up users %v, expected none", ids)
+	}
+	if patches := f.lookedUp(); len(patches) != 0 {
+		t.Errorf("lookup patches %v, expected none", patches)
+	}
+}
+
+func TestBatchesKeptForTheNextRun(t *testing.T) {
+	testutil.ChdirTemp(t)
+	if err := os.MkdirAll(static.STORAGEDIR, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	f := newFakeLookup()
+	f.err = &request.BudgetError{Endpoint: request.EndpointUsersLookup, Limit: 1}
+	p, users := newBatchPipeline(f, time.Hour)
+	ids := sendTestIds(p, 3)
+	close(p.InputUserIdsChn)
+
+	if looked := <-users; len(looked) != 0 {
+		t.Errorf("looked up users %v, expected none", looked)
+	}
+	deferred := p.progress.deferredIds()
+	if fmt.Sprint(deferred) != fmt.Sprint(ids) {
+		t.Fatalf("deferred ids %v, expected %v", deferred, ids)
+	}
+	for _, id := range ids {
+		if !storage.CheckOldUser(id) {
+			t.Errorf("deferred id %v is not in the cache", id)
+		}
+	}
+
+	// the clean stop keeps them in the checkpoint, they are looked up first by the next run
+	if err := finalCheckpoint(deferred); err != nil {
+		t.Fatal(err)
+	}
+	cp, ok := loadCheckpoint()
+	if !ok || fmt.Sprint(cp.Pending) != fmt.Sprint(ids) || cp.Investigating != 0 {
+		t.Fatalf("checkpoint %+v, expected the pending ids %v", cp, ids)
+	}
+	next := newFakeLookup()
+	p, users = newBatchPipelineFrom(next, time.Hour, cp)
+	close(p.InputUserIdsChn)
+	if looked := <-users; fmt.Sprint(looked) != fmt.Sprint(ids) {
+		t.Errorf("looked up users %v by the next run, expected %v", looked, ids)
+	}
+
+	if err := finalCheckpoint(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadCheckpoint(); ok {
+		t.Error("the checkpoint of a clean stop has not been removed")
+	}
+}
+
+func patchSizes(patches [][]int64) []int {
+	sizes := []int{}
+	for _, patch := range patches {
+		sizes = append(sizes, len(patch))
+	}
+	return sizes
+}
+
+func TestWaitRateLimit(t *testing.T) {
+	testutil.InitLogger()
+	rateLimited := func(next time.Time) error {
+		h := http.Header{}
+		h.Set("X-Rate-Limit-Reset", strconv.FormatInt(next.Unix(), 10))
+		return &anaconda.ApiError{StatusCode: http.StatusTooManyRequests, Header: h}
+	}
+	ctx := context.Background()
+
+	if waitRateLimit(ctx, errors.New("network error")) {
+		t.Error("waited for a non rate limit error")
+	}
+	if waitRateLimit(ctx, &anaconda.ApiError{StatusCode: http.StatusNotFound}) {
+		t.Error("waited for a not found error")
+	}
+	if !waitRateLimit(ctx, rateLimited(time.Now().Add(-time.Second))) {
+		t.Error("passed rate limit window not retried")
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	started := time.Now()
+	if waitRateLimit(canceled, rateLimited(time.Now().Add(time.Hour))) {
+		t.Error("retried after the pipeline has been stopped")
+	}
+	if waited := time.Since(started); waited > time.Second {
+		t.Errorf("waited %v after the pipeline has been stopped", waited)
+	}
+}
+
+// fakeFollowers : followers/following API that sends one page and returns err on the first call
+type fakeFollowers struct {
+	mtx   sync.Mutex
+	froms []request.Cursor
+	err   error
+	// done : signaled after the second call
+	done chan struct{}
+}
+
+func (f *fakeFollowers) followers(ctx context.Context, username string, userID int64, from request.Cursor, found func([]int64, request.Cursor) error) error {
+	f.mtx.Lock()
+	f.froms = append(f.froms, from)
+	calls := len(f.froms)
+	f.mtx.Unlock()
+	if calls > 1 {
+		f.done <- struct{}{}
+		return nil
+	}
+	if err := found(newTestIds(1), request.Cursor{Endpoint: request.EndpointFriendsIds, Next: "7"}); err != nil {
+		return err
+	}
+	return f.err
+}
+
+func TestRateLimitedUserResumesFromCursor(t *testing.T) {
+	loadCacheOnce.Do(func() {
+		testutil.InitLogger()
+		storage.LoadCache()
+	})
+	testutil.ChdirTemp(t)
+	old := config.Configuration()
+	t.Cleanup(func() { config.SetConfiguration(old) })
+	config.SetConfiguration(config.Config{Following: true, Followers: true})
+
+	frontier, err := storage.NewFrontier(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer frontier.Close()
+	h := http.Header{}
+	h.Set("X-Rate-Limit-Reset", strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10))
+	f := &fakeFollowers{err: &anaconda.ApiError{StatusCode: http.StatusTooManyRequests, Header: h}, done: make(chan struct{}, 1)}
+	p := NewPipeline(nil, nil)
+	p.followers = f.followers
+	p.frontier = frontier
+	p.budget = newBudget(config.Budget{}, func() {})
+	go func() {
+		for range p.InputUserIdsChn {
+		}
+	}()
+
+	user := newTestIds(1)[0]
+	frontier.AddInvestUser(storage.InvestUser{ID: user})
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		p.getUserFollowersFollowing(ctx)
+		close(p.InputUserIdsChn)
+		close(stopped)
+	}()
+	select {
+	case <-f.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the rate limited user has not been investigated again")
+	}
+	cancel()
+	waitClosed(t, stopped, "the followers/following stage")
+
+	expected := []request.Cursor{{}, {Endpoint: request.EndpointFriendsIds, Next: "7"}}
+	if fmt.Sprint(f.froms) != fmt.Sprint(expected) {
+		t.Errorf("requested from %v, expected %v", f.froms, expected)
+	}
+}
+
+// waitClosed : wait for the channel to be closed
+func waitClosed(t *testing.T, done <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%v has not been closed", what)
+	}
+}
+
+func TestStopNotStarted(t *testing.T) {
+	p := NewPipeline(nil, nil)
+	waitClosed(t, p.Stop(), "the done channel of Stop")
+	waitClosed(t, p.Done(), "the done channel")
+	if err := p.Start(context.Background()); err == nil {
+		t.Error("stopped pipeline has been started")
+	}
+}
+
+func TestStartFailureClosesDone(t *testing.T) {
+	testutil.InitLogger()
+	testutil.ChdirTemp(t)
+	old := config.Configuration()
+	t.Cleanup(func() { config.SetConfiguration(old) })
+	config.SetConfiguration(config.Config{FrontierOrder: "RANDOM"})
+
+	p := NewPipeline(nil, nil)
+	if err := p.Start(context.Background()); err == nil {
+		t.Fatal("started with unknown FRONTIER_ORDER")
+	}
+	waitClosed(t, p.Done(), "the done channel")
+	waitClosed(t, p.Stop(), "the done channel of Stop")
+}
+
+func TestStopWaitsForSeeds(t *testing.T) {
+	testutil.InitLogger()
+	testutil.ChdirTemp(t)
+	old := config.Configuration()
+	t.Cleanup(func() { config.SetConfiguration(old) })
+	config.SetConfiguration(config.Config{})
+
+	ids := newTestIds(1)
+	seeds, err := ParseSeeds([]string{fmt.Sprintf("id:%v", ids[0])})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPipeline(nil, seeds)
+	p.lookup = newFakeLookup().lookup
+	// paused, the seed is pushed but not investigated
+	p.gate.pause()
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); p.Stats().Frontier < len(ids); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("frontier of %v users, expected %v", p.Stats().Frontier, len(ids))
+		}
+	}
+	waitClosed(t, p.Stop(), "the done channel of Stop")
+
+	frontier, err := storage.NewFrontier(static.STORAGEDIR, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer frontier.Close()
+	if frontier.Len() != len(ids) {
+		t.Errorf("%v users saved in the frontier, expected the seed", frontier.Len())
+	}
+}
+
+func TestExpandDepth(t *testing.T) {
+	tests := []struct {
+		maxDepth int64
+		depth    int64
+		expand   bool
+	}{
+		{0, 0, true},
+		{0, 10, true},
+		{-1, 3, true},
+		{1, 0, true},
+		{1, 1, false},
+		{2, 1, true},
+		{2, 2, false},
+		{2, 3, false},
+	}
+	for _, tt := range tests {
+		if expand := expandDepth(config.Config{MaxDepth: tt.maxDepth}, tt.depth); expand != tt.expand {
+			t.Errorf("MAX_DEPTH %v depth %v expanded %v, expected %v", tt.maxDepth, tt.depth, expand, tt.expand)
+		}
+	}
+}
+
+func TestUserDepth(t *testing.T) {
+	loadCacheOnce.Do(func() {
+		testutil.InitLogger()
+		storage.LoadCache()
+	})
+	ids := newTestIds(2)
+	// unknown users of the older caches
+	if d := userDepth(ids[0]); d != 1 {
+		t.Errorf("unknown user depth %v, expected 1", d)
+	}
+	storage.SetUserDepth(ids[0], 2, "id:1")
+	storage.SetUserDepth(ids[0], 0, "id:2")
+	storage.SetUserDepth(ids[0], 1, "id:3")
+	if d, seed := userDepth(ids[0]), storage.UserSeed(ids[0]); d != 0 || seed != "id:2" {
+		t.Errorf("user depth %v seed %v, expected the shortest depth 0 from id:2", d, seed)
+	}
+}
+
+// newDepthPipeline : followers/following stage with fake followers that send ids as the followers of every user
+func newDepthPipeline(t *testing.T, c config.Config, followers map[int64][]int64) (*Pipeline, chan int64) {
+	t.Helper()
+	loadCacheOnce.Do(func() {
+		testutil.InitLogger()
+		storage.LoadCache()
+	})
+	old := config.Configuration()
+	t.Cleanup(func() { config.SetConfiguration(old) })
+	config.SetConfiguration(c)
+
+	frontier, err := storage.NewFrontier(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { frontier.Close() })
+	investigated := make(chan int64, 10)
+	p := NewPipeline(nil, nil)
+	p.frontier = frontier
+	p.budget = newBudget(config.Budget{}, func() {})
+	p.followers = func(ctx context.Context, username string, userID int64, from request.Cursor, found func([]int64, request.Cursor) error) error {
+		investigated <- userID
+		return found(followers[userID], request.Cursor{Endpoint: request.EndpointFollowersIds, Next: "0"})
+	}
+	go func() {
+		for range p.InputUserIdsChn {
+		}
+	}()
+	return p, investigated
+}
+
+func TestFollowersDepth(t *testing.T) {
+	ids := newTestIds(4)
+	seed, user, followers, deep := ids[0], ids[1], ids[2:3], ids[3]
+	p, investigated := newDepthPipeline(t, config.Config{MaxDepth: 2}, map[int64][]int64{seed: {user}, user: followers})
+	storage.SetUserDepth(seed, 0, "@seed")
+	storage.SetUserDepth(deep, 2, "@seed")
+	for _, id := range []int64{seed, user, deep} {
+		p.frontier.AddInvestUser(storage.InvestUser{ID: id})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		p.getUserFollowersFollowing(ctx)
+		close(p.InputUserIdsChn)
+		close(stopped)
+	}()
+	got := []int64{}
+	for len(got) < 2 {
+		select {
+		case id := <-investigated:
+			got = append(got, id)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("investigated %v, expected the seed and its follower", got)
+		}
+	}
+	// the user at MAX_DEPTH is skipped
+	for deadline := time.Now().Add(5 * time.Second); p.Stats().Skipped < 1; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the user at MAX_DEPTH has not been skipped")
+		}
+	}
+	cancel()
+	waitClosed(t, stopped, "the followers/following stage")
+	select {
+	case id := <-investigated:
+		t.Errorf("user %v investigated, expected the user at MAX_DEPTH to be skipped", id)
+	default:
+	}
+
+	// the followers are one hop deeper than the user, from the same seed
+	for id, expected := range map[int64]int64{seed: 0, user: 1, followers[0]: 2} {
+		if d := userDepth(id); d != expected {
+			t.Errorf("user %v depth %v, expected %v", id, d, expected)
+		}
+		if s := storage.UserSeed(id); s != "@seed" {
+			t.Errorf("user %v seed %q, expected @seed", id, s)
+		}
+	}
+}
+
+func TestValidateMaxDepth(t *testing.T) {
+	c := config.Config{MaxDepth: 2, Recursive: true}
+	p, _ := newDepthPipeline(t, c, nil)
+	f, err := finder.NewFinder(config.SearchCriteria{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.finder = f
+	go func() {
+		for range p.validUserChn {
+		}
+	}()
+
+	ids := newTestIds(3)
+	for i, id := range ids {
+		storage.SetUserDepth(id, int64(i), "@seed")
+	}
+	done := make(chan struct{})
+	go func() {
+		p.checkValidateUser(context.Background())
+		close(done)
+	}()
+	for _, id := range ids {
+		p.userDetailsChn <- finder.Profile{User: anaconda.User{Id: id}}
+	}
+	close(p.userDetailsChn)
+	waitClosed(t, done, "the validation stage")
+
+	// the users at MAX_DEPTH are not investigated
+	if n := p.frontier.Len(); n != 2 {
+		t.Errorf("%v users under investigation, expected the users at depth 0 and 1", n)
+	}
+	for _, expected := range ids[:2] {
+		u, err := p.frontier.RemoveInvestUser(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.ID != expected {
+			t.Errorf("user %v under investigation, expected %v", u.ID, expected)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+	"twfinder/request"
+	"twfinder/static"
+
+	"github.com/tarekbadrshalaan/goStuff/configuration"
+)
+
+const checkpointfile = "checkpoint.json"
+
+// pauseGate : the stages wait on the gate while the pipeline is paused
+type pauseGate struct {
+	mtx sync.Mutex
+	// resumed : closed on resume, nil while the pipeline is running
+	resumed chan struct{}
+}
+
+// pause : false if it is already paused
+func (g *pauseGate) pause() bool {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if g.resumed != nil {
+		return false
+	}
+	g.resumed = make(chan struct{})
+	return true
+}
+
+// resume : false if it is not paused
+func (g *pauseGate) resume() bool {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if g.resumed == nil {
+		return false
+	}
+	close(g.resumed)
+	g.resumed = nil
+	return true
+}
+
+func (g *pauseGate) paused() bool {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	return g.resumed != nil
+}
+
+// wait : wait while the pipeline is paused, it returns ctx.Err() if the context is canceled first
+func (g *pauseGate) wait(ctx context.Context) error {
+	g.mtx.Lock()
+	resumed := g.resumed
+	g.mtx.Unlock()
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// checkpoint : the in-flight state of the pipeline, saved with the cache so the next start continues
+// from the same point if the application is killed
+type checkpoint struct {
+	Paused bool      `json:"PAUSED"`
+	Saved  time.Time `json:"SAVED"`
+	// Investigating : the user whose followers/following are collected, Cursor the next page
+	Investigating int64          `json:"INVESTIGATING"`
+	Cursor        request.Cursor `json:"CURSOR"`
+	// Pending : the users ids collected and not looked up yet
+	Pending []int64 `json:"PENDING"`
+}
+
+// progress : the in-flight state of the stages
+type progress struct {
+	mtx           sync.Mutex
+	investigating int64
+	cursor        request.Cursor
+	// batch : the ids of the batch under construction, lookup the ids of the batch under lookup
+	batch  []int64
+	lookup []int64
+	// deferred : the ids that have not been looked up because the pipeline is stopping,
+	// they are already in the cache and are looked up by the next run
+	deferred []int64
+}
+
+// setCursor : the user under investigation and the next page cursor
+func (pr *progress) setCursor(id int64, cursor request.Cursor) {
+	pr.mtx.Lock()
+	defer pr.mtx.Unlock()
+	pr.investigating, pr.cursor = id, cursor
+}
+
+// investigatingCursor : the user under investigation and the next page cursor
+func (pr *progress) investigatingCursor() (int64, request.Cursor) {
+	pr.mtx.Lock()
+	defer pr.mtx.Unlock()
+	return pr.investigating, pr.cursor
+}
+
+// setBatch : the ids of the batch under construction and the ids of the batch under lookup
+func (pr *progress) setBatch(batch, lookup []int64) {
+	pr.mtx.Lock()
+	defer pr.mtx.Unlock()
+	pr.batch, pr.lookup = batch, lookup
+}
+
+// deferIds : keep the ids that have not been looked up for the next run
+func (pr *progress) deferIds(ids []int64) {
+	pr.mtx.Lock()
+	defer pr.mtx.Unlock()
+	pr.deferred = append(pr.deferred, ids...)
+}
+
+// deferredIds : the ids that have not been looked up
+func (pr *progress) deferredIds() []int64 {
+	pr.mtx.Lock()
+	defer pr.mtx.Unlock()
+	return append([]int64{}, pr.deferred...)
+}
+
+// checkpoint : copy of the in-flight state
+func (pr *progress) checkpoint(paused bool) checkpoint {
+	pr.mtx.Lock()
+	defer pr.mtx.Unlock()
+	cp := checkpoint{
+		Paused:        paused,
+		Saved:         time.Now(),
+		Investigating: pr.investigating,
+		Cursor:        pr.cursor,
+	}
+	cp.Pending = append(cp.Pending, pr.deferred...)
+	cp.Pending = append(cp.Pending, pr.lookup...)
+	cp.Pending = append(cp.Pending, pr.batch...)
+	return cp
+}
+
+func checkpointPath() string {
+	return fmt.Sprintf("%v/%v", static.STORAGEDIR, checkpointfile)
+}
+
+// saveCheckpoint : save the in-flight state with the results
+func saveCheckpoint(cp checkpoint) error {
+	data, err := json.MarshalIndent(cp, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(checkpointPath(), data, 0644)
+}
+
+// loadCheckpoint : the in-flight state of the last run if it has not been stopped cleanly
+func loadCheckpoint() (checkpoint, bool) {
+	cp := checkpoint{}
+	if err := configuration.JSON(checkpointPath(), &cp); err != nil {
+		return cp, false
+	}
+	return cp, true
+}
+
+// removeCheckpoint : the run has been stopped cleanly, the in-flight users are stored or back in the frontier
+func removeCheckpoint() {
+	os.Remove(checkpointPath())
+}
+
+// finalCheckpoint : keep the ids that have not been looked up for the next run,
+// otherwise the run has been stopped cleanly and the checkpoint is removed.
+func finalCheckpoint(deferred []int64) error {
+	if len(deferred) == 0 {
+		removeCheckpoint()
+		return nil
+	}
+	return saveCheckpoint(checkpoint{Saved: time.Now(), Pending: deferred})
+}
@@ -0,0 +1,169 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+	"twfinder/config"
+	"twfinder/finder"
+	"twfinder/request"
+	"twfinder/static"
+	"twfinder/testutil"
+)
+
+func TestPauseGate(t *testing.T) {
+	g := pauseGate{}
+	ctx := context.Background()
+	if g.paused() || g.wait(ctx) != nil {
+		t.Fatal("the gate is paused before pause")
+	}
+	if g.resume() {
+		t.Error("resumed without pause")
+	}
+	if !g.pause() || g.pause() {
+		t.Error("pause twice, expected true then false")
+	}
+	if !g.paused() {
+		t.Error("the gate is not paused")
+	}
+
+	waited := make(chan error, 1)
+	go func() { waited <- g.wait(ctx) }()
+	select {
+	case err := <-waited:
+		t.Fatalf("wait returned %v while paused", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !g.resume() || g.resume() {
+		t.Error("resume twice, expected true then false")
+	}
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Errorf("wait returned %v after resume", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait has not returned after resume")
+	}
+
+	// a canceled context ends the wait
+	g.pause()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := g.wait(canceled); err != context.Canceled {
+		t.Errorf("wait returned %v, expected context.Canceled", err)
+	}
+}
+
+func TestCheckpointSaveLoad(t *testing.T) {
+	testutil.ChdirTemp(t)
+	if err := os.MkdirAll(static.STORAGEDIR, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadCheckpoint(); ok {
+		t.Fatal("checkpoint loaded before save")
+	}
+
+	pr := progress{}
+	cursor := request.Cursor{Endpoint: request.EndpointFollowersIds, Next: "1234"}
+	pr.setCursor(7, cursor)
+	pr.setBatch([]int64{5, 6}, []int64{3, 4})
+	pr.deferIds([]int64{1, 2})
+	if err := saveCheckpoint(pr.checkpoint(true)); err != nil {
+		t.Fatal(err)
+	}
+	cp, ok := loadCheckpoint()
+	if !ok {
+		t.Fatal("checkpoint not loaded")
+	}
+	if !cp.Paused || cp.Investigating != 7 || cp.Cursor != cursor || cp.Saved.IsZero() {
+		t.Errorf("checkpoint %+v", cp)
+	}
+	// the deferred ids first, then the lookup and the batch
+	if fmt.Sprint(cp.Pending) != "[1 2 3 4 5 6]" {
+		t.Errorf("pending %v, expected [1 2 3 4 5 6]", cp.Pending)
+	}
+
+	if err := finalCheckpoint(pr.deferredIds()); err != nil {
+		t.Fatal(err)
+	}
+	if cp, ok := loadCheckpoint(); !ok || cp.Investigating != 0 || fmt.Sprint(cp.Pending) != "[1 2]" {
+		t.Errorf("final checkpoint %+v %v, expected the deferred ids only", cp, ok)
+	}
+	if err := finalCheckpoint(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadCheckpoint(); ok {
+		t.Error("checkpoint kept after a clean stop")
+	}
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	testutil.InitLogger()
+	testutil.ChdirTemp(t)
+	old := config.Configuration()
+	t.Cleanup(func() { config.SetConfiguration(old) })
+	config.SetConfiguration(config.Config{Following: true, Followers: true})
+	if err := os.MkdirAll(static.STORAGEDIR, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := newTestIds(3)
+	cursor := request.Cursor{Endpoint: request.EndpointFriendsIds, Next: "42"}
+	if err := saveCheckpoint(checkpoint{Investigating: ids[0], Cursor: cursor, Pending: ids[1:]}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := finder.NewFinder(config.SearchCriteria{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := newFakeLookup()
+	froms := make(chan string, 10)
+	p := NewPipeline(f, nil)
+	p.lookup = lookup.lookup
+	p.followers = func(ctx context.Context, username string, userID int64, from request.Cursor, found func([]int64, request.Cursor) error) error {
+		froms <- fmt.Sprint(userID, from)
+		return nil
+	}
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the user under investigation continues from the cursor
+	select {
+	case from := <-froms:
+		if expected := fmt.Sprint(ids[0], cursor); from != expected {
+			t.Errorf("investigated %v, expected %v", from, expected)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the user under investigation has not been continued")
+	}
+	// the pending users are looked up
+	select {
+	case <-lookup.called:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the pending users have not been looked up")
+	}
+	if patches := lookup.lookedUp(); fmt.Sprint(patches) != fmt.Sprint([][]int64{ids[1:]}) {
+		t.Errorf("looked up %v, expected %v", patches, ids[1:])
+	}
+
+	// the pause is checkpointed
+	if !p.Pause() || !p.Paused() {
+		t.Fatal("not paused")
+	}
+	if cp, ok := loadCheckpoint(); !ok || !cp.Paused {
+		t.Errorf("checkpoint %+v %v, expected paused", cp, ok)
+	}
+	if !p.Resume() || p.Paused() {
+		t.Error("not resumed")
+	}
+
+	waitClosed(t, p.Stop(), "the done channel of Stop")
+	if cp, ok := loadCheckpoint(); ok {
+		t.Errorf("checkpoint %+v kept after a clean stop", cp)
+	}
+}
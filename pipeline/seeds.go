@@ -0,0 +1,218 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"twfinder/config"
+	"twfinder/logger"
+	"twfinder/request"
+)
+
+// seed kinds, the entries of SEEDS e.g. "golang", "id:783214", "list:golang/gophers",
+// "following_not_followers:golang" and "file:seeds.txt" with one entry per line.
+const (
+	seedUser                  = "user"
+	seedID                    = "id"
+	seedList                  = "list"
+	seedFollowingNotFollowers = "following_not_followers"
+	seedFile                  = "file"
+)
+
+var handleRegex = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+
+// Seed : the users the pipeline starts with, every user found from the seed is attributed to it
+type Seed struct {
+	Kind string
+	// Value : the screen name, the user id or the list
+	Value string
+}
+
+func (s Seed) String() string {
+	if s.Kind == seedUser {
+		return "@" + s.Value
+	}
+	return s.Kind + ":" + s.Value
+}
+
+// Seeds : the seeds of the configuration, SEARCH_USER and SEEDS
+func Seeds(c config.Config) ([]Seed, error) {
+	entries := []string{}
+	if strings.TrimSpace(c.SearchUser) != "" {
+		entries = append(entries, c.SearchUser)
+	}
+	entries = append(entries, c.Seeds...)
+	seeds, err := ParseSeeds(entries)
+	if err != nil {
+		return nil, err
+	}
+	if len(seeds) == 0 {
+		return nil, fmt.Errorf("no seeds, set SEARCH_USER or SEEDS")
+	}
+	return seeds, nil
+}
+
+// ParseSeeds : parse the seed entries, the seed files are read
+func ParseSeeds(entries []string) ([]Seed, error) {
+	seeds := []Seed{}
+	seen := map[Seed]bool{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parsed := []Seed{}
+		if strings.HasPrefix(entry, seedFile+":") {
+			fileSeeds, err := readSeedFile(strings.TrimPrefix(entry, seedFile+":"))
+			if err != nil {
+				return nil, err
+			}
+			parsed = fileSeeds
+		} else {
+			s, err := parseSeed(entry)
+			if err != nil {
+				return nil, err
+			}
+			parsed = append(parsed, s)
+		}
+		for _, s := range parsed {
+			if !seen[s] {
+				seen[s] = true
+				seeds = append(seeds, s)
+			}
+		}
+	}
+	return seeds, nil
+}
+
+// parseSeed : parse one seed entry
+func parseSeed(entry string) (Seed, error) {
+	kind, value := seedUser, entry
+	if idx := strings.IndexByte(entry, ':'); idx >= 0 {
+		kind, value = strings.ToLower(entry[:idx]), strings.TrimSpace(entry[idx+1:])
+	}
+	switch kind {
+	case seedUser, seedFollowingNotFollowers:
+		value = strings.TrimPrefix(value, "@")
+		if !handleRegex.MatchString(value) {
+			return Seed{}, fmt.Errorf("invalid seed %q, expected twitter handle", entry)
+		}
+	case seedID:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return Seed{}, fmt.Errorf("invalid seed %q, expected user id", entry)
+		}
+	case seedList:
+		if _, _, _, err := parseList(value); err != nil {
+			return Seed{}, fmt.Errorf("invalid seed %q, %v", entry, err)
+		}
+	default:
+		return Seed{}, fmt.Errorf("unknown seed %q, expected handle, %v:, %v:, %v: or %v:",
+			entry, seedID, seedList, seedFollowingNotFollowers, seedFile)
+	}
+	return Seed{Kind: kind, Value: value}, nil
+}
+
+// parseList : list id or OWNER/SLUG
+func parseList(value string) (string, string, int64, error) {
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return "", "", id, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, "@"), "/")
+	if len(parts) != 2 || !handleRegex.MatchString(parts[0]) || parts[1] == "" {
+		return "", "", 0, fmt.Errorf("expected list id or OWNER/SLUG")
+	}
+	return parts[0], parts[1], 0, nil
+}
+
+// readSeedFile : one seed per line, '#' starts a comment line
+func readSeedFile(path string) ([]Seed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred during read the seed file: %v", err)
+	}
+	defer f.Close()
+	seeds := []Seed{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, seedFile+":") {
+			return nil, fmt.Errorf("%v: nested seed file %q", path, line)
+		}
+		s, err := parseSeed(line)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", path, err)
+		}
+		seeds = append(seeds, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return seeds, nil
+}
+
+// resolveSeeds : call found with the users ids of every seed,
+// the handles are looked up together, the requests wait for the rate limit window like the crawl.
+func resolveSeeds(ctx context.Context, seeds []Seed, found func(s Seed, ids []int64) error) error {
+	retry := func(err error) bool { return waitRateLimit(ctx, err) }
+	names := []string{}
+	byName := map[string]Seed{}
+	for _, s := range seeds {
+		if s.Kind == seedUser {
+			names = append(names, s.Value)
+			byName[strings.ToLower(s.Value)] = s
+		}
+	}
+	if len(names) > 0 {
+		users, err := request.GetUsersLookupByNames(names, retry)
+		var berr *request.BudgetError
+		if errors.As(err, &berr) {
+			return err
+		}
+		if err != nil {
+			logger.Errorf("%v\n>>> Error occurred during lookup the seeds %v", err, names)
+		}
+		for _, u := range users {
+			if err := found(byName[strings.ToLower(u.ScreenName)], []int64{u.Id}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, s := range seeds {
+		var err error
+		switch s.Kind {
+		case seedID:
+			id, _ := strconv.ParseInt(s.Value, 10, 64)
+			err = found(s, []int64{id})
+		case seedList:
+			owner, slug, id, _ := parseList(s.Value)
+			err = request.ListMembers(ctx, owner, slug, id, retry, func(ids []int64) error {
+				return found(s, ids)
+			})
+		case seedFollowingNotFollowers:
+			var ids []int64
+			if ids, err = request.FollowingNotFollowers(ctx, s.Value, retry); err == nil {
+				err = found(s, ids)
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var berr *request.BudgetError
+		if errors.As(err, &berr) {
+			return err
+		}
+		if err != nil {
+			logger.Errorf("%v\n>>> [skip seed] Error occurred during request seed:<%v>", err, s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"sync/atomic"
+	"time"
+	"twfinder/request"
+)
+
+// pipeline stages
+const (
+	StageCrawl    = "crawl"
+	StageLookup   = "lookup"
+	StageValidate = "validate"
+	StageStore    = "store"
+)
+
+// counters : the users counted by the stages, updated with sync/atomic
+type counters struct {
+	// discovered : the users ids found from the seeds and the followers/following
+	discovered int64
+	lookedUp   int64
+	// stored : the matched users stored by the storage systems
+	stored int64
+	// dropped : the users ids that have not been found e.g. the account is suspended or the lookup failed
+	dropped int64
+	// skipped : the users that have not been investigated e.g. MAX_DEPTH or request errors
+	skipped int64
+	// cacheHits : the users ids that have been checked before
+	cacheHits int64
+}
+
+func (c *counters) add(counter *int64, n int) {
+	atomic.AddInt64(counter, int64(n))
+}
+
+// Stats : snapshot of the pipeline, it is safe to take while the pipeline is running
+type Stats struct {
+	Started    time.Time `json:"STARTED"`
+	Elapsed    string    `json:"ELAPSED"`
+	Paused     bool      `json:"PAUSED"`
+	StopReason string    `json:"STOP_REASON"`
+	Discovered int64     `json:"DISCOVERED"`
+	LookedUp   int64     `json:"LOOKED_UP"`
+	Matches    int64     `json:"MATCHES"`
+	Stored     int64     `json:"STORED"`
+	Frontier   int       `json:"FRONTIER"`
+	Dropped    int64     `json:"DROPPED"`
+	Skipped    int64     `json:"SKIPPED"`
+	CacheHits  int64     `json:"CACHE_HITS"`
+	// Endpoints : the API calls, rate limit waits and errors per endpoint
+	Endpoints map[string]request.EndpointStats `json:"ENDPOINTS"`
+	Stages    []StageStats                     `json:"STAGES"`
+	// NextRateLimitWindow : the earliest rate limit window to come, zero if no endpoint is rate limited
+	NextRateLimitWindow time.Time `json:"NEXT_RATE_LIMIT_WINDOW"`
+	RateLimitETA        string    `json:"RATE_LIMIT_ETA"`
+}
+
+// StageStats : the users processed by the stage and the throughput since the pipeline started
+type StageStats struct {
+	Stage     string  `json:"STAGE"`
+	Processed int64   `json:"PROCESSED"`
+	PerMinute float64 `json:"PER_MINUTE"`
+}
+
+// Stats : snapshot of the pipeline counters, zero if it has not been started
+func (p *Pipeline) Stats() Stats {
+	if p.budget == nil {
+		return Stats{}
+	}
+	now := time.Now()
+	matches, profiles := p.budget.counts()
+	st := Stats{
+		Started:    p.budget.started,
+		Elapsed:    now.Sub(p.budget.started).Round(time.Second).String(),
+		Paused:     p.Paused(),
+		StopReason: p.budget.stopReason(),
+		Discovered: atomic.LoadInt64(&p.counters.discovered),
+		LookedUp:   atomic.LoadInt64(&p.counters.lookedUp),
+		Matches:    matches,
+		Stored:     atomic.LoadInt64(&p.counters.stored),
+		Frontier:   p.frontier.Len(),
+		Dropped:    atomic.LoadInt64(&p.counters.dropped),
+		Skipped:    atomic.LoadInt64(&p.counters.skipped),
+		CacheHits:  atomic.LoadInt64(&p.counters.cacheHits),
+		Endpoints:  p.apiStats(),
+	}
+
+	minutes := now.Sub(p.budget.started).Minutes()
+	for _, stage := range []StageStats{
+		{Stage: StageCrawl, Processed: st.Discovered},
+		{Stage: StageLookup, Processed: st.LookedUp},
+		{Stage: StageValidate, Processed: profiles},
+		{Stage: StageStore, Processed: st.Stored},
+	} {
+		if minutes > 0 {
+			stage.PerMinute = float64(stage.Processed) / minutes
+		}
+		st.Stages = append(st.Stages, stage)
+	}
+
+	for _, ep := range st.Endpoints {
+		if ep.NextWindow.After(now) && (st.NextRateLimitWindow.IsZero() || ep.NextWindow.Before(st.NextRateLimitWindow)) {
+			st.NextRateLimitWindow = ep.NextWindow
+		}
+	}
+	if !st.NextRateLimitWindow.IsZero() {
+		st.RateLimitETA = st.NextRateLimitWindow.Sub(now).Round(time.Second).String()
+	}
+	return st
+}
@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+	"twfinder/config"
+	"twfinder/request"
+	"twfinder/storage"
+	"twfinder/testutil"
+)
+
+func TestStatsNotStarted(t *testing.T) {
+	p := NewPipeline(nil, nil)
+	if st := p.Stats(); !st.Started.IsZero() || st.Stages != nil || st.Endpoints != nil {
+		t.Errorf("stats %+v of a pipeline that has not been started, expected zero", st)
+	}
+}
+
+func TestStats(t *testing.T) {
+	testutil.InitLogger()
+	frontier, err := storage.NewFrontier(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { frontier.Close() })
+	frontier.AddInvestUser(storage.InvestUser{ID: 1})
+	frontier.AddInvestUser(storage.InvestUser{ID: 2})
+
+	now := time.Now()
+	p := NewPipeline(nil, nil)
+	p.frontier = frontier
+	p.budget = newBudget(config.Budget{}, func() {})
+	p.budget.started = now.Add(-2 * time.Minute)
+	p.apiStats = func() map[string]request.EndpointStats {
+		return map[string]request.EndpointStats{
+			request.EndpointUsersLookup:  {Calls: 4, NextWindow: now.Add(-time.Minute)},
+			request.EndpointFriendsIds:   {Calls: 3, RateLimitWaits: 1, NextWindow: now.Add(10 * time.Minute)},
+			request.EndpointFollowersIds: {Calls: 2, RateLimitWaits: 1, NextWindow: now.Add(5 * time.Minute)},
+			request.EndpointListsMembers: {Calls: 1, Errors: 1},
+		}
+	}
+	p.counters.add(&p.counters.discovered, 20)
+	p.counters.add(&p.counters.lookedUp, 16)
+	p.counters.add(&p.counters.dropped, 2)
+	p.counters.add(&p.counters.skipped, 1)
+	p.counters.add(&p.counters.cacheHits, 2)
+	for i := 0; i < 16; i++ {
+		p.budget.profile()
+	}
+	// 4 matches sent to the store, one patch of 3 stored so far
+	for i := 0; i < 4; i++ {
+		p.budget.match()
+	}
+	p.counters.add(&p.counters.stored, 3)
+	p.gate.pause()
+
+	st := p.Stats()
+	if !st.Paused || st.StopReason != "" || st.Frontier != 2 {
+		t.Errorf("paused %v, stop reason %q, frontier %v, expected paused running pipeline with 2 users", st.Paused, st.StopReason, st.Frontier)
+	}
+	if st.Discovered != 20 || st.LookedUp != 16 || st.Matches != 4 || st.Stored != 3 || st.Dropped != 2 || st.Skipped != 1 || st.CacheHits != 2 {
+		t.Errorf("counters %+v", st)
+	}
+
+	expected := map[string]int64{StageCrawl: 20, StageLookup: 16, StageValidate: 16, StageStore: 3}
+	if len(st.Stages) != len(expected) {
+		t.Fatalf("stages %+v, expected %v", st.Stages, expected)
+	}
+	for _, stage := range st.Stages {
+		if stage.Processed != expected[stage.Stage] {
+			t.Errorf("stage %v processed %v, expected %v", stage.Stage, stage.Processed, expected[stage.Stage])
+		}
+		// about 2 minutes since the start
+		if perMinute := float64(expected[stage.Stage]) / 2; stage.PerMinute < perMinute*0.9 || stage.PerMinute > perMinute {
+			t.Errorf("stage %v %v per minute, expected about %v", stage.Stage, stage.PerMinute, perMinute)
+		}
+	}
+
+	// the earliest window to come, the passed window of users/lookup is ignored
+	if !st.NextRateLimitWindow.Equal(now.Add(5 * time.Minute)) {
+		t.Errorf("next rate limit window %v, expected the followers/ids window %v", st.NextRateLimitWindow, now.Add(5*time.Minute))
+	}
+	if eta, err := time.ParseDuration(st.RateLimitETA); err != nil || eta < 4*time.Minute || eta > 5*time.Minute {
+		t.Errorf("rate limit ETA %q, expected about 5m", st.RateLimitETA)
+	}
+	if ep := st.Endpoints[request.EndpointFriendsIds]; ep.RateLimitWaits != 1 || ep.Calls != 3 {
+		t.Errorf("friends/ids stats %+v", ep)
+	}
+
+	p.budget.exhausted(stopReasonStopped)
+	if st := p.Stats(); st.StopReason != stopReasonStopped {
+		t.Errorf("stop reason %q, expected %q", st.StopReason, stopReasonStopped)
+	}
+}
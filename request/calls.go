@@ -0,0 +1,119 @@
+package request
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// twitter API endpoints, the keys of the API calls count and limits
+const (
+	EndpointUsersLookup  = "users/lookup"
+	EndpointFriendsIds   = "friends/ids"
+	EndpointFollowersIds = "followers/ids"
+	EndpointListsList    = "lists/list"
+	EndpointListsMembers = "lists/members"
+)
+
+// Endpoints : all the counted endpoints
+var Endpoints = []string{EndpointUsersLookup, EndpointFriendsIds, EndpointFollowersIds, EndpointListsList, EndpointListsMembers}
+
+// BudgetError : the API calls limit of the endpoint is reached, the call has not been sent
+type BudgetError struct {
+	Endpoint string
+	Limit    int64
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("max API calls of %v (%v) reached", e.Endpoint, e.Limit)
+}
+
+// EndpointStats : the calls of the endpoint since the last SetAPICallLimits
+type EndpointStats struct {
+	Calls int64 `json:"CALLS"`
+	// RateLimitWaits : the calls failed with rate limit error, the caller waits for the next window
+	RateLimitWaits int64 `json:"RATE_LIMIT_WAITS"`
+	// Errors : the calls failed with other errors
+	Errors int64 `json:"ERRORS"`
+	// NextWindow : the next rate limit window of the last rate limit error
+	NextWindow time.Time `json:"NEXT_WINDOW"`
+}
+
+var (
+	callsMtx   sync.Mutex
+	calls      = map[string]*EndpointStats{}
+	callLimits = map[string]int64{}
+)
+
+// SetAPICallLimits : reset the API calls count for a new run with the limits per endpoint, 0 is unlimited
+func SetAPICallLimits(limits map[string]int64) {
+	callsMtx.Lock()
+	defer callsMtx.Unlock()
+	calls = map[string]*EndpointStats{}
+	callLimits = map[string]int64{}
+	for endpoint, limit := range limits {
+		callLimits[endpoint] = limit
+	}
+}
+
+// APICalls : the API calls count per endpoint since the last SetAPICallLimits
+func APICalls() map[string]int64 {
+	callsMtx.Lock()
+	defer callsMtx.Unlock()
+	res := make(map[string]int64, len(calls))
+	for endpoint, st := range calls {
+		res[endpoint] = st.Calls
+	}
+	return res
+}
+
+// APIStats : copy of the stats per endpoint since the last SetAPICallLimits
+func APIStats() map[string]EndpointStats {
+	callsMtx.Lock()
+	defer callsMtx.Unlock()
+	res := make(map[string]EndpointStats, len(calls))
+	for endpoint, st := range calls {
+		res[endpoint] = *st
+	}
+	return res
+}
+
+// countCall : count the call of the endpoint, it returns *BudgetError instead if the limit is reached
+func countCall(endpoint string) error {
+	callsMtx.Lock()
+	defer callsMtx.Unlock()
+	st, ok := calls[endpoint]
+	if !ok {
+		st = &EndpointStats{}
+		calls[endpoint] = st
+	}
+	if limit := callLimits[endpoint]; limit > 0 && st.Calls >= limit {
+		return &BudgetError{Endpoint: endpoint, Limit: limit}
+	}
+	st.Calls++
+	return nil
+}
+
+// countResult : count the rate limit error or the other errors of the endpoint call
+func countResult(endpoint string, err error) {
+	if err == nil {
+		return
+	}
+	callsMtx.Lock()
+	defer callsMtx.Unlock()
+	st, ok := calls[endpoint]
+	if !ok {
+		st = &EndpointStats{}
+		calls[endpoint] = st
+	}
+	if aerr, ok := err.(*anaconda.ApiError); ok {
+		if isRateLimitError, nextWindow := aerr.RateLimitCheck(); isRateLimitError {
+			st.RateLimitWaits++
+			st.NextWindow = nextWindow
+			return
+		}
+	}
+	st.Errors++
+}
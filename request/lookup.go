@@ -0,0 +1,77 @@
+package request
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"twfinder/finder"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// users/lookup is requested with anaconda, anaconda.User has no entities.description,
+// bioLinks reads the t.co links of the bio from the same response before anaconda decodes it.
+
+// bioLinks : the links of the bio of the users being looked up
+var bioLinks = &bioLinksTransport{base: http.DefaultTransport, links: map[int64][]finder.BioURL{}}
+
+// bioLinksTransport : keep the bio links of the users/lookup responses by user id until the lookup returns
+type bioLinksTransport struct {
+	base  http.RoundTripper
+	mtx   sync.Mutex
+	links map[int64][]finder.BioURL
+}
+
+// RoundTrip : the response body is read and given back to anaconda unchanged
+func (t *bioLinksTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK || !strings.HasSuffix(req.URL.Path, "/users/lookup.json") {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	data := body
+	// anaconda reads the deflate responses too
+	if resp.Header.Get("Content-Encoding") == "deflate" {
+		r, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return resp, nil
+		}
+		if data, err = io.ReadAll(r); err != nil {
+			return resp, nil
+		}
+	}
+	// an invalid response is reported by anaconda
+	profiles, err := finder.DecodeProfiles(data)
+	if err != nil {
+		return resp, nil
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for _, p := range profiles {
+		if len(p.BioURLs) > 0 {
+			t.links[p.Id] = p.BioURLs
+		}
+	}
+	return resp, nil
+}
+
+// profiles : the looked up users with the links of their bio
+func (t *bioLinksTransport) profiles(users []anaconda.User) []finder.Profile {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	res := make([]finder.Profile, 0, len(users))
+	for _, u := range users {
+		res = append(res, finder.Profile{User: u, BioURLs: t.links[u.Id]})
+		delete(t.links, u.Id)
+	}
+	return res
+}
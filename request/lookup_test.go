@@ -0,0 +1,70 @@
+package request
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+func TestUsersLookupKeepsBioURLs(t *testing.T) {
+	apiTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/lookup.json" {
+			t.Errorf("request %v, expected users/lookup", r.URL.Path)
+		}
+		if ids := r.URL.Query().Get("user_id"); ids != "1,2" {
+			t.Errorf("user_id %q, expected 1,2", ids)
+		}
+		w.Write([]byte(`[
+			{"id": 1, "screen_name": "one", "description": "see https://t.co/abc",
+			 "entities": {"url": {"urls": []}, "description": {"urls": [
+				{"url": "https://t.co/abc", "expanded_url": "https://github.com/one", "display_url": "github.com/one", "indices": [4, 20]}]}}},
+			{"id": 2, "screen_name": "two", "description": "no links", "entities": {"description": {"urls": []}}}
+		]`))
+	})
+
+	users, err := GetUsersLookup([]int64{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("%v users, expected 2", len(users))
+	}
+	if urls := users[0].BioURLs; len(urls) != 1 || urls[0].URL != "https://t.co/abc" || urls[0].ExpandedURL != "https://github.com/one" {
+		t.Errorf("bio urls %+v", urls)
+	}
+	if urls := users[1].BioURLs; len(urls) != 0 {
+		t.Errorf("bio urls %+v, expected none", urls)
+	}
+	for _, u := range users {
+		if len(u.Entities.Urls) != 0 {
+			t.Errorf("@%v entities urls %+v, expected none", u.ScreenName, u.Entities.Urls)
+		}
+	}
+	if len(bioLinks.links) != 0 {
+		t.Errorf("bio links %v kept after the lookup", bioLinks.links)
+	}
+}
+
+func TestUsersLookupRateLimitError(t *testing.T) {
+	reset := time.Now().Add(5 * time.Minute).Unix()
+	apiTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"errors": [{"code": 88, "message": "Rate limit exceeded"}]}`))
+	})
+
+	_, err := GetUsersLookupByNames([]string{"one"}, nil)
+	aerr, ok := err.(*anaconda.ApiError)
+	if !ok {
+		t.Fatalf("error %v, expected *anaconda.ApiError", err)
+	}
+	if isRateLimitError, nextWindow := aerr.RateLimitCheck(); !isRateLimitError || nextWindow.Unix() != reset {
+		t.Errorf("rate limit %v %v, expected the reset header", isRateLimitError, nextWindow)
+	}
+	if len(aerr.Decoded.Errors) != 1 || aerr.Decoded.Errors[0].Code != anaconda.TwitterErrorRateLimitExceeded {
+		t.Errorf("decoded errors %+v", aerr.Decoded)
+	}
+}
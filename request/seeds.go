@@ -0,0 +1,145 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"twfinder/finder"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// lookupLimit : the maximum users of one users/lookup request
+const lookupLimit = 100
+
+// RetryFunc : decide if the failed call is sent again e.g. after waiting for the rate limit window,
+// nil never retries.
+type RetryFunc func(err error) bool
+
+// retryCall : check if the failed call should be sent again
+func (retry RetryFunc) retryCall(err error) bool {
+	return retry != nil && retry(err)
+}
+
+// GetUsersLookupByNames : the users of the screen names with the links of their bio
+func GetUsersLookupByNames(names []string, retry RetryFunc) ([]finder.Profile, error) {
+	users := []finder.Profile{}
+	for start := 0; start < len(names); {
+		end := start + lookupLimit
+		if end > len(names) {
+			end = len(names)
+		}
+		if err := countCall(EndpointUsersLookup); err != nil {
+			return nil, err
+		}
+		res, err := twAPI.GetUsersLookup(strings.Join(names[start:end], ","), nil)
+		countResult(EndpointUsersLookup, err)
+		if err != nil {
+			if retry.retryCall(err) {
+				continue
+			}
+			return nil, err
+		}
+		users = append(users, bioLinks.profiles(res)...)
+		start = end
+	}
+	return users, nil
+}
+
+// ListMembers : call found with every page of the list members ids,
+// the list is the list id, or the owner screen name and the list slug.
+func ListMembers(ctx context.Context, owner, slug string, listID int64, retry RetryFunc, found func(ids []int64) error) error {
+	for listID == 0 {
+		if err := countCall(EndpointListsList); err != nil {
+			return err
+		}
+		lists, err := twAPI.GetLists(0, owner, true, nil)
+		countResult(EndpointListsList, err)
+		if err != nil {
+			if retry.retryCall(err) {
+				continue
+			}
+			return err
+		}
+		for _, l := range lists {
+			if strings.EqualFold(l.Slug, slug) && strings.EqualFold(l.User.ScreenName, owner) {
+				listID = l.Id
+				break
+			}
+		}
+		if listID == 0 {
+			return fmt.Errorf("list %v/%v not found", owner, slug)
+		}
+	}
+
+	v := url.Values{}
+	v.Set("skip_status", "true")
+	nextCursor := "-1"
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := countCall(EndpointListsMembers); err != nil {
+			return err
+		}
+		v.Set("cursor", nextCursor)
+		cursor, err := twAPI.GetListMembers("", listID, v)
+		countResult(EndpointListsMembers, err)
+		if err != nil {
+			if retry.retryCall(err) {
+				continue
+			}
+			return err
+		}
+		ids := make([]int64, 0, len(cursor.Users))
+		for _, u := range cursor.Users {
+			ids = append(ids, u.Id)
+		}
+		if err := found(ids); err != nil {
+			return err
+		}
+		nextCursor = cursor.Next_cursor_str
+		if nextCursor == "0" || nextCursor == "" {
+			return nil
+		}
+	}
+}
+
+// FollowingNotFollowers : the ids of the accounts the user follows that don't follow the user back
+func FollowingNotFollowers(ctx context.Context, username string, retry RetryFunc) ([]int64, error) {
+	v := url.Values{}
+	v.Set("screen_name", username)
+	following, err := allIds(ctx, EndpointFriendsIds, twAPI.GetFriendsIds, v, retry)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := allIds(ctx, EndpointFollowersIds, twAPI.GetFollowersIds, v, retry)
+	if err != nil {
+		return nil, err
+	}
+	isFollower := make(map[int64]bool, len(followers))
+	for _, id := range followers {
+		isFollower[id] = true
+	}
+	res := []int64{}
+	for _, id := range following {
+		if !isFollower[id] {
+			res = append(res, id)
+		}
+	}
+	return res, nil
+}
+
+// allIds : all the pages of the ids endpoint
+func allIds(ctx context.Context, endpoint string, get func(url.Values) (anaconda.Cursor, error), v url.Values, retry RetryFunc) ([]int64, error) {
+	ids := []int64{}
+	err := pages(ctx, endpoint, get, v, Cursor{}, retry, func(page []int64, next Cursor) error {
+		ids = append(ids, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,120 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// rateLimitError : 429 error of the twitter API with the next window
+func rateLimitError(next time.Time) error {
+	h := http.Header{}
+	h.Set("X-Rate-Limit-Reset", strconv.FormatInt(next.Unix(), 10))
+	return &anaconda.ApiError{StatusCode: http.StatusTooManyRequests, Header: h}
+}
+
+// fakePages : ids endpoint with the pages by cursor, the cursors in fail fail once with err
+type fakePages struct {
+	pages map[string]anaconda.Cursor
+	fail  map[string]error
+	calls []string
+}
+
+func (f *fakePages) get(v url.Values) (anaconda.Cursor, error) {
+	c := v.Get("cursor")
+	f.calls = append(f.calls, c)
+	if err, ok := f.fail[c]; ok {
+		delete(f.fail, c)
+		return anaconda.Cursor{}, err
+	}
+	return f.pages[c], nil
+}
+
+func TestAllIdsEmptyNextCursor(t *testing.T) {
+	SetAPICallLimits(nil)
+	f := &fakePages{pages: map[string]anaconda.Cursor{
+		"-1": {Ids: []int64{1, 2}, Next_cursor_str: "7"},
+		"7":  {Ids: []int64{3}, Next_cursor_str: ""},
+	}}
+	ids, err := allIds(context.Background(), EndpointFriendsIds, f.get, url.Values{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || len(f.calls) != 2 {
+		t.Errorf("ids %v after calls %v, expected 3 ids after 2 calls", ids, f.calls)
+	}
+}
+
+func TestAllIdsRetry(t *testing.T) {
+	limited := rateLimitError(time.Now())
+	newPages := func() *fakePages {
+		return &fakePages{
+			pages: map[string]anaconda.Cursor{
+				"-1": {Ids: []int64{1, 2}, Next_cursor_str: "7"},
+				"7":  {Ids: []int64{3}, Next_cursor_str: "0"},
+			},
+			fail: map[string]error{"7": limited},
+		}
+	}
+
+	SetAPICallLimits(nil)
+	f := newPages()
+	retried := []error{}
+	ids, err := allIds(context.Background(), EndpointFollowersIds, f.get, url.Values{}, func(err error) bool {
+		retried = append(retried, err)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 {
+		t.Errorf("ids %v, expected 3", ids)
+	}
+	if calls := strings.Join(f.calls, " "); calls != "-1 7 7" {
+		t.Errorf("calls %v, expected -1 7 7", calls)
+	}
+	if len(retried) != 1 || retried[0] != limited {
+		t.Errorf("retried %v, expected the rate limit error", retried)
+	}
+	if st := APIStats()[EndpointFollowersIds]; st.Calls != 3 || st.RateLimitWaits != 1 {
+		t.Errorf("stats %+v, expected 3 calls and 1 rate limit wait", st)
+	}
+
+	// without retry the error is returned
+	f = newPages()
+	if _, err := allIds(context.Background(), EndpointFollowersIds, f.get, url.Values{}, nil); !errors.Is(err, limited) {
+		t.Errorf("error %v, expected the rate limit error", err)
+	}
+}
+
+func TestGetUsersLookupByNamesRetry(t *testing.T) {
+	SetAPICallLimits(nil)
+	calls := 0
+	apiTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`[{"id": 1, "screen_name": "one"}]`))
+	})
+
+	users, err := GetUsersLookupByNames([]string{"one"}, func(err error) bool {
+		aerr, ok := err.(*anaconda.ApiError)
+		return ok && aerr.StatusCode == http.StatusTooManyRequests
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Id != 1 || calls != 2 {
+		t.Errorf("users %v after %v calls, expected user 1 after 2 calls", users, calls)
+	}
+}
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"net/http"
 	"sync"
 	"twfinder/config"
 
@@ -17,7 +18,16 @@ var (
 func TwitterAPI() *anaconda.TwitterApi {
 	buildAPIOnce.Do(func() {
 		c := config.Configuration()
-		twAPI = anaconda.NewTwitterApiWithCredentials(c.AccessToken, c.AccessTokenSecret, c.ConsumerKey, c.ConsumerSecret)
+		twAPI = configureAPI(anaconda.NewTwitterApiWithCredentials(c.AccessToken, c.AccessTokenSecret, c.ConsumerKey, c.ConsumerSecret))
 	})
 	return twAPI
 }
+
+// configureAPI : the rate limit errors are returned to the caller instead of waiting in anaconda,
+// anaconda waits for the next window without a context and a stopping pipeline would hang until then.
+// the responses go through bioLinks to keep the links of the looked up users bio.
+func configureAPI(api *anaconda.TwitterApi) *anaconda.TwitterApi {
+	api.ReturnRateLimitError(true)
+	api.HttpClient = &http.Client{Timeout: api.HttpClient.Timeout, Transport: bioLinks}
+	return api
+}
@@ -1,24 +1,39 @@
 package request
 
 import (
+	"context"
 	"net/url"
 	"strconv"
 	"twfinder/config"
+	"twfinder/finder"
 
 	"github.com/tarekbadrshalaan/anaconda"
 )
 
-// GetUsersLookup :
-func GetUsersLookup(ids []int64) ([]anaconda.User, error) {
+// GetUsersLookup : the users of the ids with the links of their bio
+func GetUsersLookup(ids []int64) ([]finder.Profile, error) {
+	if err := countCall(EndpointUsersLookup); err != nil {
+		return nil, err
+	}
 	usersProfile, err := twAPI.GetUsersLookupByIds(ids, nil)
+	countResult(EndpointUsersLookup, err)
 	if err != nil {
 		return nil, err
 	}
-	return usersProfile, nil
+	return bioLinks.profiles(usersProfile), nil
 }
 
-// UserFollowersFollowing :
-func UserFollowersFollowing(username string, userID int64, InputUserIdsChn chan<- int64) error {
+// Cursor : the position of the following/followers pages, the zero value is the first page
+type Cursor struct {
+	Endpoint string `json:"ENDPOINT"`
+	// Next : the cursor of the next page of the endpoint, "0" if there are no more pages
+	Next string `json:"NEXT"`
+}
+
+// UserFollowersFollowing : call found with every page of the user following/followers ids from the cursor
+// and the cursor of the next page, it returns the error of found or ctx.Err() if the context is canceled
+// before all the pages are sent.
+func UserFollowersFollowing(ctx context.Context, username string, userID int64, from Cursor, found func(ids []int64, next Cursor) error) error {
 	c := config.Configuration()
 
 	v := url.Values{}
@@ -29,41 +44,52 @@ func UserFollowersFollowing(username string, userID int64, InputUserIdsChn chan<
 		v.Set("screen_name", username)
 	}
 
-	if c.Following {
+	if c.Following && from.Endpoint != EndpointFollowersIds {
 		// Collect User Following
-		nextCursor := "-1"
-		for {
-			v.Set("cursor", nextCursor)
-			cursor, err := twAPI.GetFriendsIds(v)
-			if err != nil {
-				return err
-			}
-			for _, id := range cursor.Ids {
-				InputUserIdsChn <- id
-			}
-			nextCursor = cursor.Next_cursor_str
-			if nextCursor == "0" {
-				break
-			}
+		if err := pages(ctx, EndpointFriendsIds, twAPI.GetFriendsIds, v, from, nil, found); err != nil {
+			return err
 		}
 	}
 
 	if c.Followers {
 		// Collect User Followers
-		nextCursor := "-1"
-		for {
-			v.Set("cursor", nextCursor)
-			cursor, err := twAPI.GetFollowersIds(v)
-			if err != nil {
-				return err
-			}
-			for _, id := range cursor.Ids {
-				InputUserIdsChn <- id
-			}
-			nextCursor = cursor.Next_cursor_str
-			if nextCursor == "0" {
-				break
+		if err := pages(ctx, EndpointFollowersIds, twAPI.GetFollowersIds, v, from, nil, found); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pages : call found with every page of the ids endpoint, starting from the cursor if it is of the endpoint,
+// the failed page is requested again if retry allows it.
+func pages(ctx context.Context, endpoint string, get func(url.Values) (anaconda.Cursor, error), v url.Values,
+	from Cursor, retry RetryFunc, found func(ids []int64, next Cursor) error) error {
+	nextCursor := "-1"
+	if from.Endpoint == endpoint && from.Next != "" {
+		nextCursor = from.Next
+	}
+	for nextCursor != "0" {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := countCall(endpoint); err != nil {
+			return err
+		}
+		v.Set("cursor", nextCursor)
+		cursor, err := get(v)
+		countResult(endpoint, err)
+		if err != nil {
+			if retry.retryCall(err) {
+				continue
 			}
+			return err
+		}
+		nextCursor = cursor.Next_cursor_str
+		if nextCursor == "" {
+			nextCursor = "0"
+		}
+		if err := found(cursor.Ids, Cursor{Endpoint: endpoint, Next: nextCursor}); err != nil {
+			return err
 		}
 	}
 	return nil
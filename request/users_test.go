@@ -0,0 +1,63 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"twfinder/config"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+// apiTestServer : the twitter API served by handler
+func apiTestServer(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	api := configureAPI(anaconda.NewTwitterApi("token", "secret"))
+	api.SetBaseUrl(srv.URL)
+	old := twAPI
+	twAPI = api
+	t.Cleanup(func() { twAPI = old })
+}
+
+func TestUserFollowersFollowingReturnsRateLimit(t *testing.T) {
+	old := config.Configuration()
+	t.Cleanup(func() { config.SetConfiguration(old) })
+	config.SetConfiguration(config.Config{Following: true, Followers: true})
+	SetAPICallLimits(nil)
+
+	reset := time.Now().Add(15 * time.Minute).Unix()
+	apiTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/friends/ids.json" {
+			t.Errorf("request %v, expected friends/ids", r.URL.Path)
+		}
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"errors": [{"code": 88, "message": "Rate limit exceeded"}]}`))
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- UserFollowersFollowing(context.Background(), "", 1, Cursor{}, func([]int64, Cursor) error { return nil })
+	}()
+	var err error
+	select {
+	case err = <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the rate limit window is waited in anaconda")
+	}
+	aerr, ok := err.(*anaconda.ApiError)
+	if !ok {
+		t.Fatalf("error %v, expected *anaconda.ApiError", err)
+	}
+	if isRateLimitError, nextWindow := aerr.RateLimitCheck(); !isRateLimitError || nextWindow.Unix() != reset {
+		t.Errorf("rate limit %v %v, expected the reset header", isRateLimitError, nextWindow)
+	}
+	if st := APIStats()[EndpointFriendsIds]; st.Calls != 1 || st.RateLimitWaits != 1 || st.NextWindow.Unix() != reset {
+		t.Errorf("friends/ids stats %+v, expected 1 call with a rate limit wait", st)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// pauseSignals : toggle pause/resume of the running pipeline
+var pauseSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package main
+
+import "os"
+
+// pauseSignals : there is no SIGUSR1 on windows, use the GUI or the HTTP endpoint
+var pauseSignals = []os.Signal{}
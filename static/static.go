@@ -1,10 +1,14 @@
 package static
 
+import "time"
+
 const (
 	// TWITTERREQUESTSLIMIT :
 	TWITTERREQUESTSLIMIT = 900
 	// TWITTERPATCHSIZE :
 	TWITTERPATCHSIZE = 99
+	// TWITTERPATCHTIMEOUT : a partial lookup patch is flushed when its first user has waited this long
+	TWITTERPATCHTIMEOUT = 10 * time.Second
 	// RESULTPATCHSIZE :
 	RESULTPATCHSIZE = 10
 	// STORAGEDIR :
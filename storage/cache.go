@@ -11,45 +11,42 @@ import (
 )
 
 const (
-	oldusrfile     = "old_user.json"
+	oldusrfile = "old_user.json"
+	// invstusrfile : the users under investigation before the frontier, imported by NewFrontier
 	invstusrfile   = "invst_user.json"
 	successusrfile = "successful_user.json"
+	depthusrfile   = "depth_user.json"
+	seedusrfile    = "seed_user.json"
 )
 
 var oldUser map[int64]bool
-var invstUser map[int64]bool
 var successUser map[int64]bool
 var oldUserMtx sync.Mutex
-var invstUserMtx sync.Mutex
 var successUserMtx sync.Mutex
 
+// userDepth : hops from the seed, the seeds are 0 and their followers/following are 1
+var userDepth map[int64]int64
+
+// userSeed : the seed the user has been found from, with the lowest depth
+var userSeed map[int64]string
+var userDepthMtx sync.Mutex
+
 func initializeCache() {
 	if oldUser == nil {
 		oldUser = map[int64]bool{}
 	}
-	if invstUser == nil {
-		invstUser = map[int64]bool{}
-	}
 	if successUser == nil {
 		successUser = map[int64]bool{}
 	}
+	if userDepth == nil {
+		userDepth = map[int64]int64{}
+	}
+	if userSeed == nil {
+		userSeed = map[int64]string{}
+	}
 	oldUserMtx = sync.Mutex{}
-	invstUserMtx = sync.Mutex{}
 	successUserMtx = sync.Mutex{}
-}
-
-// AddInvestUser : (cache) add new user to be under investigation.
-func AddInvestUser(id int64) {
-	invstUserMtx.Lock()
-	defer invstUserMtx.Unlock()
-	invstUser[id] = true
-}
-
-// RemoveInvestUser : (cache) remove user from under investigation.
-func RemoveInvestUser(id int64) {
-	invstUserMtx.Lock()
-	defer invstUserMtx.Unlock()
-	delete(invstUser, id)
+	userDepthMtx = sync.Mutex{}
 }
 
 // AddSuccessUser : (cache) add new user to successful users.
@@ -59,6 +56,32 @@ func AddSuccessUser(id int64) {
 	successUser[id] = true
 }
 
+// SetUserDepth : (cache) set the hops from the seed and the seed the user is found from,
+// the lowest depth is kept.
+func SetUserDepth(id int64, depth int64, seed string) {
+	userDepthMtx.Lock()
+	defer userDepthMtx.Unlock()
+	if d, ok := userDepth[id]; !ok || depth < d {
+		userDepth[id] = depth
+		userSeed[id] = seed
+	}
+}
+
+// UserDepth : (cache) the hops from the search user, false if unknown.
+func UserDepth(id int64) (int64, bool) {
+	userDepthMtx.Lock()
+	defer userDepthMtx.Unlock()
+	d, ok := userDepth[id]
+	return d, ok
+}
+
+// UserSeed : (cache) the seed the user is found from, empty if unknown.
+func UserSeed(id int64) string {
+	userDepthMtx.Lock()
+	defer userDepthMtx.Unlock()
+	return userSeed[id]
+}
+
 // CheckOldUser : (cache) to check this user has been invested before.
 func CheckOldUser(id int64) bool {
 	oldUserMtx.Lock()
@@ -67,12 +90,11 @@ func CheckOldUser(id int64) bool {
 		return true
 	}
 	oldUser[id] = true
-	RemoveInvestUser(id)
 	return false
 }
 
 // LoadCache : load internal cache from files
-func LoadCache(userInvstChn chan<- int64) {
+func LoadCache() {
 	initializeCache()
 
 	oldUserMtx.Lock()
@@ -87,18 +109,16 @@ func LoadCache(userInvstChn chan<- int64) {
 	if err := configuration.JSON(successfile, &successUser); err != nil {
 		logger.Warn(err)
 	}
-	invstUserMtx.Lock()
-	defer invstUserMtx.Unlock()
-	invstfile := fmt.Sprintf("%v/%v", static.STORAGEDIR, invstusrfile)
-	if err := configuration.JSON(invstfile, &invstUser); err != nil {
+	userDepthMtx.Lock()
+	defer userDepthMtx.Unlock()
+	depthfile := fmt.Sprintf("%v/%v", static.STORAGEDIR, depthusrfile)
+	if err := configuration.JSON(depthfile, &userDepth); err != nil {
+		logger.Warn(err)
+	}
+	seedfile := fmt.Sprintf("%v/%v", static.STORAGEDIR, seedusrfile)
+	if err := configuration.JSON(seedfile, &userSeed); err != nil {
 		logger.Warn(err)
 	}
-	// push users under investigation from the cache
-	go func(userInvstChn chan<- int64) {
-		for k := range invstUser {
-			userInvstChn <- k
-		}
-	}(userInvstChn)
 	logger.Info("Cache has been loaded")
 }
 
@@ -111,13 +131,6 @@ func UpdateCache() error {
 		logger.Error(err)
 		return err
 	}
-	invstUserMtx.Lock()
-	defer invstUserMtx.Unlock()
-	invstfile := fmt.Sprintf("%v/%v", static.STORAGEDIR, invstusrfile)
-	if err := helper.SaveReplaceJsonFile(invstUser, invstfile); err != nil {
-		logger.Error(err)
-		return err
-	}
 	successUserMtx.Lock()
 	defer successUserMtx.Unlock()
 	successfile := fmt.Sprintf("%v/%v", static.STORAGEDIR, successusrfile)
@@ -125,5 +138,17 @@ func UpdateCache() error {
 		logger.Error(err)
 		return err
 	}
+	userDepthMtx.Lock()
+	defer userDepthMtx.Unlock()
+	depthfile := fmt.Sprintf("%v/%v", static.STORAGEDIR, depthusrfile)
+	if err := helper.SaveReplaceJsonFile(userDepth, depthfile); err != nil {
+		logger.Error(err)
+		return err
+	}
+	seedfile := fmt.Sprintf("%v/%v", static.STORAGEDIR, seedusrfile)
+	if err := helper.SaveReplaceJsonFile(userSeed, seedfile); err != nil {
+		logger.Error(err)
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,324 @@
+package storage
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"twfinder/logger"
+	"twfinder/static"
+
+	"github.com/tarekbadrshalaan/goStuff/configuration"
+)
+
+// the frontier is the queue of the users under investigation (their followers/following are not collected yet),
+// every change is appended to a journal file under the result directory and the journal is compacted
+// on every cache update, so no user is lost when the queue grows or the application restarts.
+// the journal is synced to the disk every batch of changes and on every compaction (checkpoint).
+
+const (
+	frontierfile = "frontier.log"
+	// journalBatch : the journal is synced every batch of changes
+	journalBatch = static.TWITTERPATCHSIZE
+	// importedSuffix : the old cache file is renamed once it is imported
+	importedSuffix = ".imported"
+)
+
+// frontier orders
+const (
+	// FrontierFIFO : breadth first, the oldest user first (default)
+	FrontierFIFO = "FIFO"
+	// FrontierLIFO : depth first, the newest user first
+	FrontierLIFO = "LIFO"
+	// FrontierScore : best first, the highest match score first
+	FrontierScore = "SCORE"
+	// FrontierFollowers : best first, the most followed user first
+	FrontierFollowers = "FOLLOWERS"
+)
+
+// FrontierOrders : all the valid frontier orders
+var FrontierOrders = []string{FrontierFIFO, FrontierLIFO, FrontierScore, FrontierFollowers}
+
+// InvestUser : user under investigation
+type InvestUser struct {
+	ID        int64
+	Score     float64
+	Followers int
+	// seq : the order the user has been added
+	seq int64
+}
+
+// Frontier : persistent priority queue of the users under investigation
+type Frontier struct {
+	mtx     sync.Mutex
+	path    string
+	journal *os.File
+	users   investHeap
+	queued  map[int64]bool
+	nextSeq int64
+	// unsynced : the changes appended to the journal since the last sync
+	unsynced int
+	// ready : signaled when a user is added
+	ready chan struct{}
+}
+
+// NewFrontier : load the frontier of the directory with the order,
+// the users of the old cache file (invst_user.json) are imported if there is no frontier yet.
+func NewFrontier(dir, order string) (*Frontier, error) {
+	less, err := frontierLess(order)
+	if err != nil {
+		return nil, err
+	}
+	f := &Frontier{
+		path:   fmt.Sprintf("%v/%v", dir, frontierfile),
+		users:  investHeap{less: less},
+		queued: map[int64]bool{},
+		ready:  make(chan struct{}, 1),
+	}
+	imported := ""
+	if _, err := os.Stat(f.path); os.IsNotExist(err) {
+		imported = f.importInvestUsers(fmt.Sprintf("%v/%v", dir, invstusrfile))
+	} else if err := f.load(); err != nil {
+		return nil, err
+	}
+	heap.Init(&f.users)
+	if err := f.compact(); err != nil {
+		return nil, err
+	}
+	// the users are in the journal now, the old cache file is not imported again
+	if imported != "" {
+		if err := os.Rename(imported, imported+importedSuffix); err != nil {
+			logger.Errorf("Error occurred during rename the imported %v %v", imported, err)
+		}
+	}
+	logger.Infof("Frontier has been loaded, %v users under investigation (%v)", f.users.Len(), strings.ToUpper(order))
+	return f, nil
+}
+
+// frontierLess : the order of the users, empty is FIFO
+func frontierLess(order string) (func(a, b InvestUser) bool, error) {
+	switch strings.ToUpper(order) {
+	case "", FrontierFIFO:
+		return func(a, b InvestUser) bool { return a.seq < b.seq }, nil
+	case FrontierLIFO:
+		return func(a, b InvestUser) bool { return a.seq > b.seq }, nil
+	case FrontierScore:
+		return func(a, b InvestUser) bool {
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			return a.seq < b.seq
+		}, nil
+	case FrontierFollowers:
+		return func(a, b InvestUser) bool {
+			if a.Followers != b.Followers {
+				return a.Followers > b.Followers
+			}
+			return a.seq < b.seq
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown FRONTIER_ORDER %q, expected one of %v", order, FrontierOrders)
+}
+
+// AddInvestUser : add the user to be under investigation, it is ignored if it is already queued.
+func (f *Frontier) AddInvestUser(u InvestUser) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.queued[u.ID] {
+		return
+	}
+	u.seq = f.nextSeq
+	f.nextSeq++
+	f.queued[u.ID] = true
+	heap.Push(&f.users, u)
+	f.write(fmt.Sprintf("+ %v %v %v %v\n", u.ID, u.Score, u.Followers, u.seq))
+	select {
+	case f.ready <- struct{}{}:
+	default:
+	}
+}
+
+// RemoveInvestUser : remove the next user by the frontier order,
+// it waits until a user is added or the context is canceled.
+func (f *Frontier) RemoveInvestUser(ctx context.Context) (InvestUser, error) {
+	for {
+		f.mtx.Lock()
+		if f.users.Len() > 0 {
+			u := heap.Pop(&f.users).(InvestUser)
+			delete(f.queued, u.ID)
+			f.write(fmt.Sprintf("- %v\n", u.ID))
+			f.mtx.Unlock()
+			return u, nil
+		}
+		f.mtx.Unlock()
+		select {
+		case <-f.ready:
+		case <-ctx.Done():
+			return InvestUser{}, ctx.Err()
+		}
+	}
+}
+
+// Len : the number of users under investigation
+func (f *Frontier) Len() int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.users.Len()
+}
+
+// Save : compact the journal to the users under investigation
+func (f *Frontier) Save() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.compact()
+}
+
+// Close : compact and close the journal
+func (f *Frontier) Close() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	err := f.compact()
+	if f.journal != nil {
+		f.journal.Close()
+		f.journal = nil
+	}
+	return err
+}
+
+// write : append the change to the journal
+func (f *Frontier) write(line string) {
+	if f.journal == nil {
+		return
+	}
+	if _, err := f.journal.WriteString(line); err != nil {
+		logger.Errorf("Error occurred during write the frontier journal %v", err)
+		return
+	}
+	f.unsynced++
+	if f.unsynced >= journalBatch {
+		if err := f.journal.Sync(); err != nil {
+			logger.Errorf("Error occurred during sync the frontier journal %v", err)
+		}
+		f.unsynced = 0
+	}
+}
+
+// load : replay the journal
+func (f *Frontier) load() error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	users := map[int64]InvestUser{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		var u InvestUser
+		switch {
+		case strings.HasPrefix(text, "+ "):
+			if _, err := fmt.Sscan(text[2:], &u.ID, &u.Score, &u.Followers, &u.seq); err != nil {
+				// the last line of an interrupted write
+				logger.Warnf("%v line %v: %v", f.path, line, err)
+				continue
+			}
+			users[u.ID] = u
+			if u.seq >= f.nextSeq {
+				f.nextSeq = u.seq + 1
+			}
+		case strings.HasPrefix(text, "- "):
+			if _, err := fmt.Sscan(text[2:], &u.ID); err == nil {
+				delete(users, u.ID)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	for id, u := range users {
+		f.queued[id] = true
+		f.users.items = append(f.users.items, u)
+	}
+	return nil
+}
+
+// importInvestUsers : the users under investigation of the old cache file, the path if it is imported
+func (f *Frontier) importInvestUsers(path string) string {
+	old := map[int64]bool{}
+	if err := configuration.JSON(path, &old); err != nil {
+		return ""
+	}
+	for id := range old {
+		f.queued[id] = true
+		f.users.items = append(f.users.items, InvestUser{ID: id, seq: f.nextSeq})
+		f.nextSeq++
+	}
+	logger.Infof("%v users under investigation have been imported from %v", len(old), path)
+	return path
+}
+
+// compact : rewrite the journal with the queued users only,
+// the new journal is synced before it replaces the old one.
+func (f *Frontier) compact() error {
+	tmp := f.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	for _, u := range f.users.items {
+		fmt.Fprintf(w, "+ %v %v %v %v\n", u.ID, u.Score, u.Followers, u.seq)
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	file.Close()
+	if f.journal != nil {
+		f.journal.Close()
+		f.journal = nil
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(f.path))
+	f.unsynced = 0
+	f.journal, err = os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0644)
+	return err
+}
+
+// syncDir : sync the directory so the renamed journal survives a crash,
+// the error is ignored, the directory sync is not supported on every platform e.g. windows.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// investHeap : container/heap of the users by the frontier order
+type investHeap struct {
+	items []InvestUser
+	less  func(a, b InvestUser) bool
+}
+
+func (h investHeap) Len() int            { return len(h.items) }
+func (h investHeap) Less(i, j int) bool  { return h.less(h.items[i], h.items[j]) }
+func (h investHeap) Swap(i, j int)       { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *investHeap) Push(x interface{}) { h.items = append(h.items, x.(InvestUser)) }
+func (h *investHeap) Pop() interface{} {
+	n := len(h.items)
+	u := h.items[n-1]
+	h.items = h.items[:n-1]
+	return u
+}
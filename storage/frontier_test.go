@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestFrontier : frontier of the directory, closed at the end of the test
+func newTestFrontier(t *testing.T, dir, order string) *Frontier {
+	t.Helper()
+	initTest()
+	f, err := NewFrontier(dir, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+// removeAll : remove the users in the frontier order
+func removeAll(t *testing.T, f *Frontier) []int64 {
+	t.Helper()
+	ids := []int64{}
+	for f.Len() > 0 {
+		u, err := f.RemoveInvestUser(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+// journalLines : the lines of the frontier journal
+func journalLines(t *testing.T, dir string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, frontierfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestFrontierOrders(t *testing.T) {
+	users := []InvestUser{
+		{ID: 1, Score: 1, Followers: 300},
+		{ID: 2, Score: 3, Followers: 100},
+		{ID: 3, Score: 2, Followers: 300},
+		{ID: 4, Score: 3, Followers: 200},
+	}
+	tests := []struct {
+		order    string
+		expected string
+	}{
+		{"", "[1 2 3 4]"},
+		{FrontierFIFO, "[1 2 3 4]"},
+		{FrontierLIFO, "[4 3 2 1]"},
+		// ties in the added order
+		{FrontierScore, "[2 4 3 1]"},
+		{"followers", "[1 3 4 2]"},
+	}
+	for _, tt := range tests {
+		f := newTestFrontier(t, t.TempDir(), tt.order)
+		for _, u := range users {
+			f.AddInvestUser(u)
+		}
+		// already queued
+		f.AddInvestUser(InvestUser{ID: 1, Score: 10, Followers: 1000})
+		if ids := fmt.Sprint(removeAll(t, f)); ids != tt.expected {
+			t.Errorf("order %q: %v, expected %v", tt.order, ids, tt.expected)
+		}
+	}
+
+	if _, err := NewFrontier(t.TempDir(), "RANDOM"); err == nil {
+		t.Error("unknown order accepted")
+	}
+}
+
+func TestFrontierReplayAfterCrash(t *testing.T) {
+	dir := t.TempDir()
+	f := newTestFrontier(t, dir, FrontierScore)
+	for i, score := range []float64{1, 5, 3, 4} {
+		f.AddInvestUser(InvestUser{ID: int64(i + 1), Score: score, Followers: i * 10})
+	}
+	if u, _ := f.RemoveInvestUser(context.Background()); u.ID != 2 {
+		t.Fatalf("removed %v, expected 2", u.ID)
+	}
+	// the application is killed before Close, the last write is interrupted
+	journal, err := os.OpenFile(filepath.Join(dir, frontierfile), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal.WriteString("+ 9 0.")
+	journal.Close()
+
+	replayed := newTestFrontier(t, dir, FrontierScore)
+	if replayed.Len() != 3 {
+		t.Fatalf("%v users replayed, expected 3", replayed.Len())
+	}
+	// the new users are added after the replayed ones
+	replayed.AddInvestUser(InvestUser{ID: 5, Score: 4})
+	if ids := fmt.Sprint(removeAll(t, replayed)); ids != "[4 5 3 1]" {
+		t.Errorf("replayed %v, expected [4 5 3 1]", ids)
+	}
+}
+
+func TestFrontierCompaction(t *testing.T) {
+	dir := t.TempDir()
+	f := newTestFrontier(t, dir, "")
+	for id := int64(1); id <= 5; id++ {
+		f.AddInvestUser(InvestUser{ID: id})
+	}
+	for i := 0; i < 3; i++ {
+		f.RemoveInvestUser(context.Background())
+	}
+	if lines := journalLines(t, dir); len(lines) != 8 {
+		t.Errorf("journal %v, expected 5 added and 3 removed", lines)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	lines := journalLines(t, dir)
+	sort.Strings(lines)
+	if fmt.Sprint(lines) != "[+ 4 0 0 3 + 5 0 0 4]" {
+		t.Errorf("compacted journal %q, expected the 2 queued users", lines)
+	}
+	// the journal is appended after the compaction
+	f.AddInvestUser(InvestUser{ID: 6})
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, frontierfile+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("temp journal left after the compaction: %v", err)
+	}
+	if ids := fmt.Sprint(removeAll(t, newTestFrontier(t, dir, ""))); ids != "[4 5 6]" {
+		t.Errorf("reloaded %v, expected [4 5 6]", ids)
+	}
+}
+
+func TestFrontierImportInvestUsers(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, invstusrfile), []byte(`{"7": true, "8": true, "9": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := newTestFrontier(t, dir, "")
+	if f.Len() != 3 {
+		t.Fatalf("%v users imported, expected 3", f.Len())
+	}
+	// the old cache file is renamed once the users are in the journal
+	if _, err := os.Stat(filepath.Join(dir, invstusrfile)); !os.IsNotExist(err) {
+		t.Errorf("%v kept after the import: %v", invstusrfile, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, invstusrfile+importedSuffix)); err != nil {
+		t.Errorf("%v not renamed: %v", invstusrfile, err)
+	}
+	f.RemoveInvestUser(context.Background())
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// the frontier journal is used once it exists
+	if n := newTestFrontier(t, dir, "").Len(); n != 2 {
+		t.Errorf("%v users after reload, expected 2 (not imported again)", n)
+	}
+	// the renamed file is not imported even without the journal
+	if err := os.Remove(filepath.Join(dir, frontierfile)); err != nil {
+		t.Fatal(err)
+	}
+	if n := newTestFrontier(t, dir, "").Len(); n != 0 {
+		t.Errorf("%v users without the journal, expected 0", n)
+	}
+}
+
+func TestFrontierJournalSync(t *testing.T) {
+	dir := t.TempDir()
+	f := newTestFrontier(t, dir, "")
+	for id := int64(1); id < journalBatch; id++ {
+		f.AddInvestUser(InvestUser{ID: id})
+	}
+	if f.unsynced != journalBatch-1 {
+		t.Errorf("%v unsynced changes, expected %v", f.unsynced, journalBatch-1)
+	}
+	// the batch is synced
+	f.RemoveInvestUser(context.Background())
+	if f.unsynced != 0 {
+		t.Errorf("%v unsynced changes after the batch, expected 0", f.unsynced)
+	}
+	f.AddInvestUser(InvestUser{ID: journalBatch})
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if f.unsynced != 0 {
+		t.Errorf("%v unsynced changes after the checkpoint, expected 0", f.unsynced)
+	}
+	if n := len(journalLines(t, dir)); n != journalBatch-1 {
+		t.Errorf("%v lines in the journal, expected %v", n, journalBatch-1)
+	}
+}
+
+func TestFrontierRemoveWaits(t *testing.T) {
+	f := newTestFrontier(t, t.TempDir(), "")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		f.AddInvestUser(InvestUser{ID: 42})
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if u, err := f.RemoveInvestUser(ctx); err != nil || u.ID != 42 {
+		t.Errorf("removed %v %v, expected 42", u.ID, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.RemoveInvestUser(ctx); err != context.Canceled {
+		t.Errorf("error %v, expected context.Canceled", err)
+	}
+}
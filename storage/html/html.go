@@ -7,8 +7,6 @@ import (
 	"twfinder/logger"
 	"twfinder/static"
 	"twfinder/storage"
-
-	"github.com/tarekbadrshalaan/anaconda"
 )
 
 type html struct {
@@ -18,7 +16,7 @@ type html struct {
 
 // StorageObj :
 type StorageObj struct {
-	Users        []anaconda.User
+	Users        []storage.Result
 	PreviousPage int
 	NextPage     int
 }
@@ -49,7 +47,7 @@ func BuildHTMLStore() (storage.IStorage, error) {
 }
 
 // Store :
-func (h *html) Store(users []anaconda.User) {
+func (h *html) Store(users []storage.Result) {
 	str := StorageObj{
 		PreviousPage: h.pagecount - 1,
 		NextPage:     h.pagecount + 1,
@@ -27,6 +27,17 @@ a:hover {
 	color: white;
 	float: right;
 }
+
+.report {
+	width: 700px;
+	color: #ccd6dd;
+	font-family: monospace;
+	font-size: 12px;
+}
+
+.fail {
+	color: #e0245e;
+}
 </style>
 </head>
 
@@ -38,6 +49,22 @@ a:hover {
 			<blockquote class="twitter-tweet">
 				<a class="twitter-timeline" data-tweet-limit="1" data-width="700" data-dnt="true" data-theme="dark" href="https://twitter.com/{{ .ScreenName}}"></a>
 			</blockquote>
+			<table class="report">
+				<tr><td>SCORE</td><td>{{.Report.Score}}</td></tr>
+				<tr><td>DEPTH</td><td>{{.Depth}}</td></tr>
+				<tr><td>SEED</td><td>{{.Seed | html}}</td></tr>
+				<tr><td>PLACE</td><td>{{.Report.Place | html}}</td></tr>
+				<tr><td>BOT SCORE</td><td>{{.Report.Bot | html}}</td></tr>
+				<tr><td>LANGUAGE</td><td>{{with .Report.Language}}{{if .Code}}{{.Code}} ({{.Confidence}}){{else}}-{{end}}{{end}}</td></tr>
+				{{range .Report.Filters}}
+				<tr{{if not .Passed}} class="fail"{{end}}>
+					<td>{{.Filter | html}}</td>
+					<td>{{if .Passed}}PASS{{else}}FAIL{{end}}</td>
+					<td>{{.Decider | html}}</td>
+					<td>{{.Value | html}}</td>
+				</tr>
+				{{end}}
+			</table>
 		{{end}}
 		<a href="{{.PreviousPage}}.html" class="previous">&laquo; Previous</a>
 		<a href="{{.NextPage}}.html" class="next">Next &raquo;</a>
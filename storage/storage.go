@@ -1,21 +1,31 @@
 package storage
 
 import (
+	"sort"
+	"twfinder/finder"
 	"twfinder/logger"
 	"twfinder/static"
-
-	"github.com/tarekbadrshalaan/anaconda"
 )
 
 var (
 	// internal storage object
 	intStorage []IStorage
-	usersPatch []anaconda.User
+	usersPatch []Result
 )
 
+// Result : matched user with the report of the search criteria
+type Result struct {
+	finder.Profile
+	Report finder.MatchReport `json:"REPORT"`
+	// Depth : hops from the seed
+	Depth int64 `json:"DEPTH"`
+	// Seed : the seed the user has been found from e.g. "@golang", "list:golang/gophers"
+	Seed string `json:"SEED"`
+}
+
 // IStorage :
 type IStorage interface {
-	Store(usersList []anaconda.User)
+	Store(usersList []Result)
 }
 
 // RegisterStorage : add new storage system
@@ -26,21 +36,41 @@ func RegisterStorage(storage IStorage) {
 // Store : store successful users into the targets
 // - save to memory storage 'successUser'
 // - store patch with in registered systems
-func Store(usersChan <-chan anaconda.User) {
-	for {
-		user := <-usersChan
+// stored is called with the size of every stored patch, nil to ignore.
+// it returns when usersChan is closed, after the last partial patch is stored.
+func Store(usersChan <-chan Result, stored func(n int)) {
+	for user := range usersChan {
 		AddSuccessUser(user.Id)
 
 		usersPatch = append(usersPatch, user)
 		if len(usersPatch) >= static.RESULTPATCHSIZE {
-			for _, str := range intStorage {
-				str.Store(usersPatch)
-			}
-			logger.Infof("[Store Patch] Start User (%v) https://twitter.com/%v",
-				usersPatch[0].Id, usersPatch[0].ScreenName)
-			logger.Infof("[Store Patch] End User (%v) https://twitter.com/%v",
-				usersPatch[len(usersPatch)-1].Id, usersPatch[len(usersPatch)-1].ScreenName)
-			usersPatch = []anaconda.User{}
+			storePatch(stored)
 		}
 	}
+	storePatch(stored)
+	logger.Info("[Store Patch] all the results have been stored")
+	// the storage systems are registered again on the next start
+	intStorage = nil
+}
+
+// storePatch : store the current patch in the registered systems
+func storePatch(stored func(n int)) {
+	if len(usersPatch) == 0 {
+		return
+	}
+	// highest score first within the patch (scoring mode), the stored patches are not sorted again
+	sort.SliceStable(usersPatch, func(i, j int) bool {
+		return usersPatch[i].Report.Score > usersPatch[j].Report.Score
+	})
+	for _, str := range intStorage {
+		str.Store(usersPatch)
+	}
+	logger.Infof("[Store Patch] Start User (%v) https://twitter.com/%v",
+		usersPatch[0].Id, usersPatch[0].ScreenName)
+	logger.Infof("[Store Patch] End User (%v) https://twitter.com/%v",
+		usersPatch[len(usersPatch)-1].Id, usersPatch[len(usersPatch)-1].ScreenName)
+	if stored != nil {
+		stored(len(usersPatch))
+	}
+	usersPatch = []Result{}
 }
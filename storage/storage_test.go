@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"sync"
+	"testing"
+	"twfinder/finder"
+	"twfinder/static"
+	"twfinder/testutil"
+
+	"github.com/tarekbadrshalaan/anaconda"
+)
+
+var initTestOnce sync.Once
+
+// initTest : empty logger and cache
+func initTest() {
+	initTestOnce.Do(func() {
+		testutil.InitLogger()
+		initializeCache()
+	})
+}
+
+// patchesStorage : record the stored patches
+type patchesStorage struct {
+	patches [][]Result
+}
+
+func (s *patchesStorage) Store(users []Result) {
+	s.patches = append(s.patches, append([]Result{}, users...))
+}
+
+func TestStorePatchesSortedByScore(t *testing.T) {
+	initTest()
+	// ids in the order they are found, ids 10 and 12 have the same score
+	scores := []float64{1, 5, 3, 5, 2, 0, 4, 1, 5, 3, 9, 3}
+	if len(scores) <= static.RESULTPATCHSIZE {
+		t.Fatalf("%v results, expected more than one patch of %v", len(scores), static.RESULTPATCHSIZE)
+	}
+	st := &patchesStorage{}
+	RegisterStorage(st)
+	results := make(chan Result, len(scores))
+	for i, score := range scores {
+		results <- Result{Profile: finder.Profile{User: anaconda.User{Id: int64(i + 1)}}, Report: finder.MatchReport{Score: score}}
+	}
+	close(results)
+	stored := []int{}
+	Store(results, func(n int) { stored = append(stored, n) })
+
+	if len(st.patches) != 2 {
+		t.Fatalf("%v patches, expected 2", len(st.patches))
+	}
+	// every page is sorted by score, highest first, the pages keep the order the users are found
+	expected := [][]int64{
+		{2, 4, 9, 7, 3, 10, 5, 1, 8, 6},
+		{11, 12},
+	}
+	for i, patch := range st.patches {
+		ids := make([]int64, 0, len(patch))
+		for _, r := range patch {
+			ids = append(ids, r.Id)
+		}
+		if len(ids) != len(expected[i]) {
+			t.Fatalf("patch %v: ids %v, expected %v", i, ids, expected[i])
+		}
+		for j := range ids {
+			if ids[j] != expected[i][j] {
+				t.Errorf("patch %v: ids %v, expected %v", i, ids, expected[i])
+				break
+			}
+		}
+	}
+	if len(stored) != 2 || stored[0] != static.RESULTPATCHSIZE || stored[1] != 2 {
+		t.Errorf("stored patches of %v, expected %v and 2", stored, static.RESULTPATCHSIZE)
+	}
+	if intStorage != nil {
+		t.Error("the storage systems are still registered after Store")
+	}
+	if !successUser[1] {
+		t.Error("stored user is not in the success users")
+	}
+}
@@ -53,7 +53,7 @@ func BuildTwitterStore() (storage.IStorage, error) {
 }
 
 // Store :
-func (t *twitterStore) Store(users []anaconda.User) {
+func (t *twitterStore) Store(users []storage.Result) {
 	screenNames := []string{}
 	for _, v := range users {
 		screenNames = append(screenNames, v.ScreenName)
@@ -0,0 +1,29 @@
+// Package testutil : helpers shared by the tests of the packages
+package testutil
+
+import (
+	"os"
+	"testing"
+	"twfinder/logger"
+)
+
+// InitLogger : initialize the logger without output
+func InitLogger() {
+	l := logger.NewEmptyLogger()
+	logger.InitializeLogger(&l)
+}
+
+// ChdirTemp : run the test in a temp directory, the working directory is restored at the end of the test
+func ChdirTemp(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}